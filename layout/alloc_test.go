@@ -47,3 +47,73 @@ func TestFlexAllocs(t *testing.T) {
 		t.Errorf("expected no allocs, got %f", allocs)
 	}
 }
+
+func TestInsetAllocs(t *testing.T) {
+	var ops op.Ops
+	allocs := testing.AllocsPerRun(1, func() {
+		ops.Reset()
+		gtx := Context{
+			Ops: &ops,
+		}
+		UniformInset(10).Layout(gtx, func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Point{X: 50, Y: 50}}
+		})
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocs, got %f", allocs)
+	}
+}
+
+func TestDebugAllocs(t *testing.T) {
+	var ops op.Ops
+	allocs := testing.AllocsPerRun(1, func() {
+		ops.Reset()
+		gtx := Context{
+			Ops: &ops,
+		}
+		Debug{}.Layout(gtx, func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Point{X: 50, Y: 50}}
+		})
+		Debug{}.outline(gtx, image.Point{X: 50, Y: 50})
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocs, got %f", allocs)
+	}
+}
+
+func TestRowAllocs(t *testing.T) {
+	var ops op.Ops
+	w := func(gtx Context) Dimensions {
+		return Dimensions{Size: image.Point{X: 50, Y: 50}}
+	}
+	allocs := testing.AllocsPerRun(1, func() {
+		ops.Reset()
+		gtx := Context{
+			Ops: &ops,
+		}
+		Row{Gap: 8}.Layout(gtx, w, w)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocs, got %f", allocs)
+	}
+}
+
+func TestFitsAllocs(t *testing.T) {
+	var ops op.Ops
+	w := func(gtx Context) Dimensions {
+		op.InvalidateOp{}.Add(gtx.Ops)
+		return Dimensions{Size: image.Point{X: 50, Y: 50}}
+	}
+	allocs := testing.AllocsPerRun(1, func() {
+		ops.Reset()
+		gtx := Context{
+			Ops:         &ops,
+			Constraints: Constraints{Max: image.Point{X: 40, Y: 40}},
+		}
+		gtx.Fits(w)
+		gtx.FirstThatFits(w, w)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocs, got %f", allocs)
+	}
+}
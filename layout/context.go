@@ -4,11 +4,15 @@ package layout
 
 import (
 	"image"
+	"math"
 	"time"
 
+	"gioui.org/f32"
 	"gioui.org/io/event"
+	"gioui.org/io/pointer"
 	"gioui.org/io/system"
 	"gioui.org/op"
+	"gioui.org/op/clip"
 	"gioui.org/unit"
 )
 
@@ -26,6 +30,10 @@ type Context struct {
 	Queue event.Queue
 	// Now is the animation time.
 	Now time.Time
+	// WindowSize is the size of the window in pixels, including any
+	// area covered by system insets. Floating widgets such as popovers
+	// can use it to stay within the window.
+	WindowSize image.Point
 
 	// Locale provides information on the system's language preferences.
 	// BUG(whereswaldon): this field is not currently populated automatically.
@@ -43,9 +51,15 @@ type Context struct {
 //	  Queue: e.Queue,
 //	  Config: e.Config,
 //	  Constraints: Exact(e.Size),
+//	  WindowSize: e.Size,
 //	}
 //
-// NewContext calls ops.Reset and adjusts ops for e.Insets.
+// NewContext calls ops.Reset and adjusts ops for e.Insets. The insets
+// are the safe area reported by the platform: the edges of the window
+// covered by notches or system bars, in dp. The operations are offset by
+// the top and left insets, and the constraints are the window size less
+// all four insets, so widgets laid out with the context stay within the
+// safe area. WindowSize is the full window size.
 func NewContext(ops *op.Ops, e system.FrameEvent) Context {
 	ops.Reset()
 
@@ -69,6 +83,7 @@ func NewContext(ops *op.Ops, e system.FrameEvent) Context {
 		Queue:       e.Queue,
 		Metric:      e.Metric,
 		Constraints: Exact(size),
+		WindowSize:  e.Size,
 	}
 }
 
@@ -77,11 +92,44 @@ func (c Context) Dp(v unit.Dp) int {
 	return c.Metric.Dp(v)
 }
 
-// Sp converts v to pixels.
+// Sp converts v to pixels. Unlike dp, sp also scale with the text size
+// preferred by the user, as reported by FontScale. Use Sp for text and
+// sizes that should grow with it, and Dp for sizes that shouldn't, such
+// as the box of an icon.
 func (c Context) Sp(v unit.Sp) int {
 	return c.Metric.Sp(v)
 }
 
+// FontScale returns the scale of sp relative to dp, which is the text
+// size preferred by the user. It is 1 if the platform reports no
+// preference.
+func (c Context) FontScale() float32 {
+	// A zero scale maps units 1-to-1 to pixels, as in unit.Metric.
+	ppdp, ppsp := c.Metric.PxPerDp, c.Metric.PxPerSp
+	if ppdp == 0 {
+		ppdp = 1
+	}
+	if ppsp == 0 {
+		ppsp = 1
+	}
+	return ppsp / ppdp
+}
+
+// ExactDp returns the Exact constraints of a width and height in dp,
+// converted to pixels as by Dp.
+func (c Context) ExactDp(width, height unit.Dp) Constraints {
+	return Exact(image.Pt(c.Dp(width), c.Dp(height)))
+}
+
+// ConstraintsDp returns the constraints of minimum and maximum widths and
+// heights in dp, converted to pixels as by Dp.
+func (c Context) ConstraintsDp(minWidth, maxWidth, minHeight, maxHeight unit.Dp) Constraints {
+	return Constraints{
+		Min: image.Pt(c.Dp(minWidth), c.Dp(minHeight)),
+		Max: image.Pt(c.Dp(maxWidth), c.Dp(maxHeight)),
+	}
+}
+
 // Events returns the events available for the key. If no
 // queue is configured, Events returns nil.
 func (c Context) Events(k event.Tag) []event.Event {
@@ -100,3 +148,293 @@ func (c Context) Disabled() Context {
 	c.Queue = nil
 	return c
 }
+
+// Fork returns a copy of the context that records its operations into
+// a separate operation list, and the recording of those operations.
+// Stop the returned MacroOp after laying out with the fork, and add the
+// resulting CallOp to c.Ops to replay the forked operations, or discard
+// it.
+//
+// A fork can be laid out in a different goroutine than the context it
+// was forked from. The Constraints, Metric, Now and Locale fields are
+// copied and safe to use concurrently, but the Queue is shared and
+// event queues are in general not safe for concurrent use. Forks laid
+// out concurrently should therefore be disabled with Disabled.
+func (c Context) Fork() (Context, op.MacroOp) {
+	c.Ops = new(op.Ops)
+	return c, op.Record(c.Ops)
+}
+
+// Record lays out w and returns its dimensions and its recorded
+// operations, without adding them to c.Ops. Add the CallOp to lay out
+// the widget at the current position in the operation list, for example
+// after its siblings to draw it on top of them.
+func (c Context) Record(w Widget) (Dimensions, op.CallOp) {
+	macro := op.Record(c.Ops)
+	dims := w(c)
+	return dims, macro.Stop()
+}
+
+// Fits measures w and reports whether its size is within the
+// constraints of c. The widget is measured with a disabled copy of c, and
+// the operations of the measurement are discarded, so that the caller can
+// choose which widget to lay out, such as a compact variant of a widget
+// that doesn't fit.
+func (c Context) Fits(w Widget) (Dimensions, bool) {
+	// The recorded measurement is never called, and so never executed.
+	macro := op.Record(c.Ops)
+	dims := w(c.Disabled())
+	macro.Stop()
+	cs := c.Constraints
+	fits := dims.Size.X >= cs.Min.X && dims.Size.Y >= cs.Min.Y &&
+		dims.Size.X <= cs.Max.X && dims.Size.Y <= cs.Max.Y
+	return dims, fits
+}
+
+// FirstThatFits lays out the first of widgets whose size fits the
+// constraints of c as reported by Fits, or the last widget if none fit.
+// It returns the index of the widget laid out and its dimensions. The
+// widgets are measured in order, and the widget laid out is laid out a
+// second time.
+func (c Context) FirstThatFits(widgets ...Widget) (int, Dimensions) {
+	if len(widgets) == 0 {
+		return -1, Dimensions{}
+	}
+	i := 0
+	for ; i < len(widgets)-1; i++ {
+		if _, ok := c.Fits(widgets[i]); ok {
+			break
+		}
+	}
+	return i, widgets[i](c)
+}
+
+// Offset lays out w with its operations offset by p, and returns its
+// dimensions unchanged.
+func (c Context) Offset(p image.Point, w Widget) Dimensions {
+	defer op.Offset(p).Push(c.Ops).Pop()
+	return w(c)
+}
+
+// ClippedOffset lays out w clipped to r and with its operations offset
+// by p, and returns its dimensions unchanged. The clip is in the
+// coordinates of c, before the offset, which is what a scrolling
+// viewport needs.
+func (c Context) ClippedOffset(r image.Rectangle, p image.Point, w Widget) Dimensions {
+	defer clip.Rect(r).Push(c.Ops).Pop()
+	return c.Offset(p, w)
+}
+
+// InputArea registers a rectangular pointer input area of size for tag,
+// receiving events of types. The events are returned by Events.
+//
+// The area is popped before InputArea returns, so areas registered by
+// InputArea are siblings: the area registered last is foremost and
+// receives the events where areas overlap. Register an area inside a
+// pointer.PassOp to pass its events on to the areas below it. Use
+// Context.Offset to position the area.
+func (c Context) InputArea(size image.Point, tag event.Tag, types pointer.Type) {
+	defer clip.Rect{Max: size}.Push(c.Ops).Pop()
+	pointer.InputOp{Tag: tag, Types: types}.Add(c.Ops)
+}
+
+// Scaled lays out w zoomed by factor. The widget is laid out with its
+// constraints divided by factor and drawn with a matching scale
+// transformation, so that its sizes in pixels and device independent
+// units are both zoomed. Pointer events are transformed back by the
+// router. The dimensions are in the coordinates of c. A factor of zero
+// or less is treated as 1.
+func (c Context) Scaled(factor float32, w Widget) Dimensions {
+	if factor <= 0 || factor == 1 {
+		return w(c)
+	}
+	cs := c.Constraints
+	down := func(v int, round func(float64) float64) int {
+		if v >= Inf {
+			return v
+		}
+		// Factors below 1 must not scale constraints beyond Inf.
+		return int(math.Min(round(float64(v)/float64(factor)), Inf))
+	}
+	scs := Constraints{
+		Min: image.Pt(down(cs.Min.X, math.Ceil), down(cs.Min.Y, math.Ceil)),
+		Max: image.Pt(down(cs.Max.X, math.Floor), down(cs.Max.Y, math.Floor)),
+	}
+	scs.Min = scs.Constrain(scs.Min)
+	c.Constraints = scs
+	s := f32.Affine2D{}.Scale(f32.Point{}, f32.Pt(factor, factor))
+	trans := op.Affine(s).Push(c.Ops)
+	dims := w(c)
+	trans.Pop()
+	up := func(v int, round func(float64) float64) int {
+		return int(round(float64(v) * float64(factor)))
+	}
+	sz := cs.Constrain(image.Pt(up(dims.Size.X, math.Ceil), up(dims.Size.Y, math.Ceil)))
+	return Dimensions{
+		Size:         sz,
+		Baseline:     up(dims.Baseline, math.Round),
+		LastBaseline: up(dims.lastBaseline(), math.Round),
+	}
+}
+
+// Rotated lays out w rotated counter clockwise by radians about its
+// center, and returns the dimensions of the bounding box of the rotated
+// widget. The widget is positioned within the bounding box.
+//
+// Rotations by an exact number of quarter turns are pixel exact, and
+// for odd quarter turns the widget is laid out with its width and height
+// constraints swapped. Other rotations lay out the widget with the
+// constraints of c, and the bounding box is rounded up to whole pixels.
+// Note that the bounding box of such rotations may then overflow the
+// constraints of c, since they are not enforced on it. Only rotations by
+// whole turns keep the baselines.
+func (c Context) Rotated(radians float32, w Widget) Dimensions {
+	cs := c.Constraints
+	sin, cos := math.Sincos(float64(radians))
+	turns := math.Round(float64(radians) / (math.Pi / 2))
+	quarter := math.Abs(float64(radians)-turns*math.Pi/2) < 1e-6
+	if quarter {
+		sin, cos = math.Sincos(turns * math.Pi / 2)
+		sin, cos = math.Round(sin), math.Round(cos)
+		if sin != 0 {
+			c.Constraints = Constraints{
+				Min: image.Pt(cs.Min.Y, cs.Min.X),
+				Max: image.Pt(cs.Max.Y, cs.Max.X),
+			}
+		}
+	}
+	if quarter && sin == 0 && cos == 1 {
+		return w(c)
+	}
+	macro := op.Record(c.Ops)
+	dims := w(c)
+	call := macro.Stop()
+	width, height := float64(dims.Size.X), float64(dims.Size.Y)
+	bounds := image.Pt(
+		int(math.Ceil(math.Abs(width*cos)+math.Abs(height*sin)-1e-6)),
+		int(math.Ceil(math.Abs(width*sin)+math.Abs(height*cos)-1e-6)),
+	)
+	// Map the widget center to the center of the bounds.
+	ox := float64(bounds.X)/2 - (cos*width-sin*height)/2
+	oy := float64(bounds.Y)/2 - (sin*width+cos*height)/2
+	s, co := float32(sin), float32(cos)
+	defer op.Affine(f32.NewAffine2D(co, -s, float32(ox), s, co, float32(oy))).Push(c.Ops).Pop()
+	call.Add(c.Ops)
+	return Dimensions{Size: bounds}
+}
+
+// LayoutFixed lays out w with Exact constraints of size, ignoring the
+// constraints of c, and returns size as the size of its dimensions. Note
+// that the result may overflow the constraints of c, since they are
+// deliberately not enforced.
+func (c Context) LayoutFixed(size image.Point, w Widget) Dimensions {
+	c.Constraints = Exact(size)
+	dims := w(c)
+	dims.LastBaseline = dims.lastBaseline() + size.Y - dims.Size.Y
+	dims.Baseline += size.Y - dims.Size.Y
+	dims.Size = size
+	return dims
+}
+
+// Fraction returns the fraction f of the maximum constraint of axis in
+// pixels, rounded to the nearest pixel and clamped to the constraint. It
+// returns 0 for an unbounded axis, which has no meaningful fraction.
+//
+// Rounding each fraction of a split may leave a pixel over. Size the
+// parts of a split by differences of cumulative fractions instead, such
+// as Fraction(axis, a+b) - Fraction(axis, a) for the part after a, to
+// fill the space exactly.
+func (c Context) Fraction(axis Axis, f float32) int {
+	_, max := axis.mainConstraint(c.Constraints)
+	if max >= Inf {
+		return 0
+	}
+	v := int(math.Round(float64(f) * float64(max)))
+	if v < 0 {
+		v = 0
+	}
+	if v > max {
+		v = max
+	}
+	return v
+}
+
+// OffsetBatch lays out each of widgets with its operations offset by the
+// offset at the same index, and returns the dimensions of the bounding
+// box of the widgets at their offsets. It is equivalent to calling Offset
+// for each widget, but adds a single transformation per widget instead
+// of a pushed and popped pair. The widgets must leave the transformation
+// stack balanced, as widgets do by popping what they push.
+func (c Context) OffsetBatch(offsets []image.Point, widgets []Widget) Dimensions {
+	if len(offsets) != len(widgets) {
+		panic("layout: mismatched number of offsets and widgets")
+	}
+	defer op.TransformOp{}.Push(c.Ops).Pop()
+	var prev image.Point
+	var bounds image.Rectangle
+	for i, w := range widgets {
+		off := offsets[i]
+		op.Offset(off.Sub(prev)).Add(c.Ops)
+		prev = off
+		dims := w(c)
+		bounds = bounds.Union(image.Rectangle{Min: off, Max: off.Add(dims.Size)})
+	}
+	return Dimensions{Size: bounds.Max}
+}
+
+// Unbounded lays out w with the constraints of axis unbounded as by
+// Constraints.Unbounded. Widgets must not try to fill an unbounded
+// maximum, and the dimensions are returned unconstrained.
+func (c Context) Unbounded(axis Axis, w Widget) Dimensions {
+	c.Constraints = c.Constraints.Unbounded(axis)
+	return w(c)
+}
+
+// EqualSize measures widgets and returns their dimensions, and a widget
+// that lays them out one after the other along axis, each with its main
+// axis size fixed to the largest measured size. The largest size is
+// limited so that the widgets fit the main axis maximum constraint of c.
+//
+// The widgets are measured with a disabled copy of c, and the operations
+// of the measurement are discarded.
+func (c Context) EqualSize(axis Axis, widgets []Widget) ([]Dimensions, Widget) {
+	if len(widgets) == 0 {
+		return nil, func(gtx Context) Dimensions {
+			return Dimensions{Size: gtx.Constraints.Min}
+		}
+	}
+	// The recorded measurement is never called, and so never executed.
+	macro := op.Record(c.Ops)
+	mgtx := c.Disabled()
+	crossMin, crossMax := axis.crossConstraint(c.Constraints)
+	_, mainMax := axis.mainConstraint(c.Constraints)
+	mgtx.Constraints = axis.constraints(0, mainMax, crossMin, crossMax)
+	dims := make([]Dimensions, len(widgets))
+	size := 0
+	for i, w := range widgets {
+		dims[i] = w(mgtx)
+		if s := axis.Convert(dims[i].Size).X; s > size {
+			size = s
+		}
+	}
+	macro.Stop()
+	if max := mainMax / len(widgets); size > max {
+		size = max
+	}
+	return dims, func(gtx Context) Dimensions {
+		crossMin, crossMax := axis.crossConstraint(gtx.Constraints)
+		gtx.Constraints = axis.constraints(size, size, crossMin, crossMax)
+		var main, cross int
+		for _, w := range widgets {
+			dims := gtx.Offset(axis.Convert(image.Pt(main, 0)), w)
+			main += size
+			if c := axis.Convert(dims.Size).Y; c > cross {
+				cross = c
+			}
+		}
+		if cross < crossMin {
+			cross = crossMin
+		}
+		return Dimensions{Size: axis.Convert(image.Pt(main, cross))}
+	}
+}
@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build debuglayout
+
+package layout
+
+// DebugBounds enables the outlines drawn by Debug. It is true when
+// building with the debuglayout tag.
+const DebugBounds = true
@@ -75,8 +75,8 @@ func ExampleFlex() {
 	)
 
 	// Output:
-	// Rigid: {(0,100) (100,100)}
-	// 50%: {(45,100) (45,100)}
+	// Rigid: W[0,100] H[100,100]
+	// 50%: W[45,45] H[100,100]
 }
 
 func ExampleStack() {
@@ -100,7 +100,7 @@ func ExampleStack() {
 	)
 
 	// Output:
-	// Expand: {(50,50) (100,100)}
+	// Expand: W[50,100] H[50,100]
 }
 
 func ExampleList() {
@@ -6,6 +6,7 @@ import (
 	"image"
 
 	"gioui.org/op"
+	"gioui.org/unit"
 )
 
 // Flex lays out child elements along an axis,
@@ -28,6 +29,7 @@ type Flex struct {
 type FlexChild struct {
 	flex   bool
 	weight float32
+	min    unit.Dp
 
 	widget Widget
 
@@ -39,21 +41,27 @@ type FlexChild struct {
 // Spacing determine the spacing mode for a Flex.
 type Spacing uint8
 
+// Spacing distributes the space left over when the children of a Flex
+// don't fill its minimum main axis constraint. In the descriptions below,
+// space is the left over space and n is the number of children.
 const (
 	// SpaceEnd leaves space at the end.
 	SpaceEnd Spacing = iota
 	// SpaceStart leaves space at the start.
 	SpaceStart
-	// SpaceSides shares space between the start and end.
+	// SpaceSides shares space between the start and end,
+	// space/2 at each side.
 	SpaceSides
 	// SpaceAround distributes space evenly between children,
-	// with half as much space at the start and end.
+	// with half as much space at the start and end: space/n
+	// between children and space/(2n) at each side.
 	SpaceAround
 	// SpaceBetween distributes space evenly between children,
-	// leaving no space at the start and end.
+	// leaving no space at the start and end: space/(n-1) between
+	// children.
 	SpaceBetween
 	// SpaceEvenly distributes space evenly between children and
-	// at the start and end.
+	// at the start and end: space/(n+1) in each gap.
 	SpaceEvenly
 )
 
@@ -77,6 +85,15 @@ func Flexed(weight float32, widget Widget) FlexChild {
 	}
 }
 
+// Min returns a copy of a Flexed child with a minimum main axis size.
+// The minimum sizes of Flexed children are allocated before the
+// remaining space is distributed by weight. If the space doesn't fit the
+// minimum sizes, they are shrunk proportionally.
+func (c FlexChild) Min(min unit.Dp) FlexChild {
+	c.min = min
+	return c
+}
+
 // Layout a list of children. The position of the children are
 // determined by the specified order, but Rigid children are laid out
 // before Flexed children.
@@ -87,11 +104,13 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	crossMin, crossMax := f.Axis.crossConstraint(cs)
 	remaining := mainMax
 	var totalWeight float32
+	var minTotal int
 	cgtx := gtx
 	// Lay out Rigid children.
 	for i, child := range children {
 		if child.flex {
 			totalWeight += child.weight
+			minTotal += gtx.Dp(child.min)
 			continue
 		}
 		macro := op.Record(gtx.Ops)
@@ -112,17 +131,26 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	}
 	// fraction is the rounding error from a Flex weighting.
 	var fraction float32
-	flexTotal := remaining
+	flexTotal := remaining - minTotal
+	// minScale shrinks minimum sizes that don't fit.
+	minScale := float32(1)
+	if flexTotal < 0 {
+		minScale = float32(remaining) / float32(minTotal)
+		flexTotal = 0
+	}
 	// Lay out Flexed children.
 	for i, child := range children {
 		if !child.flex {
 			continue
 		}
 		var flexSize int
-		if remaining > 0 && totalWeight > 0 {
-			// Apply weight and add any leftover fraction from a
-			// previous Flexed.
-			childSize := float32(flexTotal) * child.weight / totalWeight
+		if remaining > 0 {
+			// Apply minimum and weight and add any leftover fraction
+			// from a previous Flexed.
+			childSize := float32(gtx.Dp(child.min)) * minScale
+			if totalWeight > 0 {
+				childSize += float32(flexTotal) * child.weight / totalWeight
+			}
 			flexSize = int(childSize + fraction + .5)
 			fraction = childSize - float32(flexSize)
 			if flexSize > remaining {
@@ -143,7 +171,7 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 		children[i].dims = dims
 	}
 	maxCross := crossMin
-	var maxBaseline int
+	var maxBaseline, maxLastBaseline int
 	for _, child := range children {
 		if c := f.Axis.Convert(child.dims.Size).Y; c > maxCross {
 			maxCross = c
@@ -151,6 +179,9 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 		if b := child.dims.Size.Y - child.dims.Baseline; b > maxBaseline {
 			maxBaseline = b
 		}
+		if b := child.dims.Size.Y - child.dims.lastBaseline(); b > maxLastBaseline {
+			maxLastBaseline = b
+		}
 	}
 	var space int
 	if mainMin > size {
@@ -171,18 +202,15 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	}
 	for i, child := range children {
 		dims := child.dims
-		b := dims.Size.Y - dims.Baseline
-		var cross int
-		switch f.Alignment {
-		case End:
-			cross = maxCross - f.Axis.Convert(dims.Size).Y
-		case Middle:
-			cross = (maxCross - f.Axis.Convert(dims.Size).Y) / 2
-		case Baseline:
-			if f.Axis == Horizontal {
-				cross = maxBaseline - b
+		// Baseline alignment only applies to horizontal layouts.
+		var b, maxB int
+		if f.Axis == Horizontal {
+			b, maxB = dims.Size.Y-dims.Baseline, maxBaseline
+			if f.Alignment == LastBaseline {
+				b, maxB = dims.Size.Y-dims.lastBaseline(), maxLastBaseline
 			}
 		}
+		cross := f.Alignment.Position(f.Axis.Convert(dims.Size).Y, maxCross, b, maxB)
 		pt := f.Axis.Convert(image.Pt(mainSize, cross))
 		trans := op.Offset(pt).Push(gtx.Ops)
 		child.call.Add(gtx.Ops)
@@ -217,7 +245,98 @@ func (f Flex) Layout(gtx Context, children ...FlexChild) Dimensions {
 	}
 	sz := f.Axis.Convert(image.Pt(mainSize, maxCross))
 	sz = cs.Constrain(sz)
-	return Dimensions{Size: sz, Baseline: sz.Y - maxBaseline}
+	return Dimensions{
+		Size:         sz,
+		Baseline:     sz.Y - maxBaseline,
+		LastBaseline: sz.Y - maxLastBaseline,
+	}
+}
+
+// Row lays out widgets one after the other along an axis, with a fixed
+// gap between them. It is a simpler alternative to a Flex of Rigid
+// children separated by Spacers.
+type Row struct {
+	// Axis is the main axis, either Horizontal or Vertical.
+	Axis Axis
+	// Gap is the space between adjacent widgets.
+	Gap unit.Dp
+	// Alignment is the alignment in the cross axis.
+	Alignment Alignment
+}
+
+type rowChild struct {
+	call op.CallOp
+	dims Dimensions
+}
+
+// Layout widgets. Each widget is laid out with a maximal main axis
+// constraint of the space left by the widgets before it.
+func (r Row) Layout(gtx Context, widgets ...Widget) Dimensions {
+	cs := gtx.Constraints
+	_, mainMax := r.Axis.mainConstraint(cs)
+	crossMin, crossMax := r.Axis.crossConstraint(cs)
+	gap := gtx.Dp(r.Gap)
+	// Avoid allocating for short rows.
+	var buf [8]rowChild
+	children := buf[:0]
+	remaining := mainMax
+	cgtx := gtx
+	maxCross := crossMin
+	var maxBaseline, maxLastBaseline int
+	for i, w := range widgets {
+		if i > 0 {
+			remaining -= gap
+			if remaining < 0 {
+				remaining = 0
+			}
+		}
+		macro := op.Record(gtx.Ops)
+		cgtx.Constraints = r.Axis.constraints(0, remaining, crossMin, crossMax)
+		dims := w(cgtx)
+		call := macro.Stop()
+		sz := r.Axis.Convert(dims.Size)
+		remaining -= sz.X
+		if remaining < 0 {
+			remaining = 0
+		}
+		if sz.Y > maxCross {
+			maxCross = sz.Y
+		}
+		if b := dims.Size.Y - dims.Baseline; b > maxBaseline {
+			maxBaseline = b
+		}
+		if b := dims.Size.Y - dims.lastBaseline(); b > maxLastBaseline {
+			maxLastBaseline = b
+		}
+		children = append(children, rowChild{call: call, dims: dims})
+	}
+	var mainSize int
+	for i, child := range children {
+		if i > 0 {
+			mainSize += gap
+		}
+		dims := child.dims
+		// Baseline alignment only applies to horizontal layouts.
+		var b, maxB int
+		if r.Axis == Horizontal {
+			b, maxB = dims.Size.Y-dims.Baseline, maxBaseline
+			if r.Alignment == LastBaseline {
+				b, maxB = dims.Size.Y-dims.lastBaseline(), maxLastBaseline
+			}
+		}
+		cross := r.Alignment.Position(r.Axis.Convert(dims.Size).Y, maxCross, b, maxB)
+		pt := r.Axis.Convert(image.Pt(mainSize, cross))
+		trans := op.Offset(pt).Push(gtx.Ops)
+		child.call.Add(gtx.Ops)
+		trans.Pop()
+		mainSize += r.Axis.Convert(dims.Size).X
+	}
+	sz := cs.Constrain(r.Axis.Convert(image.Pt(mainSize, maxCross)))
+	return Dimensions{
+		Size:         sz,
+		Baseline:     sz.Y - maxBaseline,
+		LastBaseline: sz.Y - maxLastBaseline,
+	}
 }
 
 func (s Spacing) String() string {
@@ -231,7 +350,7 @@ func (s Spacing) String() string {
 	case SpaceAround:
 		return "SpaceAround"
 	case SpaceBetween:
-		return "SpaceAround"
+		return "SpaceBetween"
 	case SpaceEvenly:
 		return "SpaceEvenly"
 	default:
@@ -4,9 +4,13 @@ package layout
 
 import (
 	"image"
+	"image/color"
+	"strconv"
 
 	"gioui.org/f32"
 	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
 	"gioui.org/unit"
 )
 
@@ -18,18 +22,47 @@ import (
 // size dimensions instead. Parent widgets should deal appropriately
 // with child widgets that return dimensions that do not fit their
 // constraints (for example, by clipping).
+//
+// Constraints are comparable with ==.
 type Constraints struct {
 	Min, Max image.Point
 }
 
+// Inf is the maximum constraint in pixels of an unbounded axis, such as
+// the main axis of a List. It is large enough to never limit a widget,
+// and small enough that offsets and sizes derived from it don't
+// overflow. Constraints methods leave an Inf maximum unbounded.
+const Inf = 1_000_000
+
 // Dimensions are the resolved size and baseline for a widget.
 //
 // Baseline is the distance from the bottom of a widget to the baseline of
-// any text it contains (or 0). The purpose is to be able to align text
-// that span multiple widgets.
+// the first line of any text it contains (or 0). The purpose is to be
+// able to align text that span multiple widgets.
+//
+// LastBaseline is the distance from the bottom of a widget to the
+// baseline of the last line of text it contains (or 0). Layouts treat a
+// zero LastBaseline as equal to Baseline, so that widgets reporting only
+// Baseline get a LastBaseline once laid out by them. Layouts report
+// LastBaseline the same way they report Baseline.
+//
+// Cells is the number of grid cells a widget occupies in a monospaced,
+// terminal like layout, such as 2 for a wide CJK character. Pixel based
+// widgets and layouts leave it zero. Layouts that return the dimensions
+// of their child unchanged, such as Context.Offset, preserve it.
 type Dimensions struct {
-	Size     image.Point
-	Baseline int
+	Size         image.Point
+	Baseline     int
+	LastBaseline int
+	Cells        int
+}
+
+// lastBaseline returns LastBaseline, or Baseline if LastBaseline is zero.
+func (d Dimensions) lastBaseline() int {
+	if d.LastBaseline == 0 {
+		return d.Baseline
+	}
+	return d.LastBaseline
 }
 
 // Axis is the Horizontal or Vertical direction.
@@ -51,6 +84,9 @@ const (
 	End
 	Middle
 	Baseline
+	// LastBaseline is like Baseline, but aligns the baselines of the
+	// last lines of text, as reported by Dimensions.LastBaseline.
+	LastBaseline
 )
 
 const (
@@ -78,6 +114,12 @@ func Exact(size image.Point) Constraints {
 	}
 }
 
+// AxisConstraints returns the Constraints with the main and cross axis
+// ranges placed according to axis.
+func AxisConstraints(axis Axis, mainMin, mainMax, crossMin, crossMax int) Constraints {
+	return axis.constraints(mainMin, mainMax, crossMin, crossMax)
+}
+
 // FPt converts an point to a f32.Point.
 func FPt(p image.Point) f32.Point {
 	return f32.Point{
@@ -102,6 +144,20 @@ func (c Constraints) Constrain(size image.Point) image.Point {
 	return size
 }
 
+// Exact reports whether the minimum and maximum constraints are equal,
+// leaving a widget no choice of size.
+func (c Constraints) Exact() bool {
+	return c.Min == c.Max
+}
+
+// Unbounded returns a copy of Constraints with the minimum constraint of
+// axis cleared and its maximum constraint set to Inf. The other axis is
+// unchanged.
+func (c Constraints) Unbounded(axis Axis) Constraints {
+	crossMin, crossMax := axis.crossConstraint(c)
+	return axis.constraints(0, Inf, crossMin, crossMax)
+}
+
 // AddMin returns a copy of Constraints with the Min constraint enlarged by up to delta
 // while still fitting within the Max constraint. The Max is unchanged, and the Min constraint
 // will not go negative.
@@ -119,19 +175,43 @@ func (c Constraints) AddMin(delta image.Point) Constraints {
 
 // SubMax returns a copy of Constraints with the Max constraint shrunk by up to delta
 // while not going negative. The values of delta are expected to be positive.
-// The Min constraint is adjusted to fit within the new Max constraint.
+// The Min constraint is adjusted to fit within the new Max constraint. An Inf
+// Max constraint is unchanged.
 func (c Constraints) SubMax(delta image.Point) Constraints {
-	c.Max = c.Max.Sub(delta)
-	if c.Max.X < 0 {
-		c.Max.X = 0
-	}
-	if c.Max.Y < 0 {
-		c.Max.Y = 0
-	}
+	c.Max.X = subMax(c.Max.X, delta.X)
+	c.Max.Y = subMax(c.Max.Y, delta.Y)
 	c.Min = c.Constrain(c.Min)
 	return c
 }
 
+// subMax subtracts delta from a maximum constraint, not going negative. An
+// Inf maximum is unchanged, and a maximum below Inf doesn't grow beyond
+// it.
+func subMax(max, delta int) int {
+	if max == Inf {
+		return max
+	}
+	below := max < Inf
+	max -= delta
+	if max < 0 {
+		max = 0
+	}
+	// A negative delta, from AddMax, must not exceed Inf.
+	if below && max > Inf {
+		max = Inf
+	}
+	return max
+}
+
+// AddMax returns a copy of Constraints with the Max constraint enlarged by
+// delta, while not going negative. An Inf Max constraint is unchanged and
+// a Max constraint below Inf doesn't grow beyond it. The Min constraint is
+// adjusted to fit within the new Max constraint, so that AddMax with a
+// negative delta is equivalent to SubMax with the positive delta.
+func (c Constraints) AddMax(delta image.Point) Constraints {
+	return c.SubMax(delta.Mul(-1))
+}
+
 // Inset adds space around a widget by decreasing its maximum
 // constraints. The minimum constraints will be adjusted to ensure
 // they do not exceed the maximum.
@@ -145,32 +225,34 @@ func (in Inset) Layout(gtx Context, w Widget) Dimensions {
 	right := gtx.Dp(in.Right)
 	bottom := gtx.Dp(in.Bottom)
 	left := gtx.Dp(in.Left)
-	mcs := gtx.Constraints
-	mcs.Max.X -= left + right
-	if mcs.Max.X < 0 {
+	if top|right|bottom|left == 0 {
+		dims := w(gtx)
+		dims.LastBaseline = dims.lastBaseline()
+		return dims
+	}
+	cs := gtx.Constraints
+	gtx.Constraints = cs.SubMax(image.Pt(left+right, top+bottom))
+	// Drop insets that don't fit.
+	if left+right > cs.Max.X {
 		left = 0
 		right = 0
-		mcs.Max.X = 0
-	}
-	if mcs.Min.X > mcs.Max.X {
-		mcs.Min.X = mcs.Max.X
 	}
-	mcs.Max.Y -= top + bottom
-	if mcs.Max.Y < 0 {
-		bottom = 0
+	if top+bottom > cs.Max.Y {
 		top = 0
-		mcs.Max.Y = 0
-	}
-	if mcs.Min.Y > mcs.Max.Y {
-		mcs.Min.Y = mcs.Max.Y
+		bottom = 0
 	}
-	gtx.Constraints = mcs
-	trans := op.Offset(image.Pt(left, top)).Push(gtx.Ops)
-	dims := w(gtx)
-	trans.Pop()
+	dims := gtx.Offset(image.Pt(left, top), w)
 	return Dimensions{
-		Size:     dims.Size.Add(image.Point{X: right + left, Y: top + bottom}),
-		Baseline: dims.Baseline + bottom,
+		Size:         dims.Size.Add(image.Point{X: right + left, Y: top + bottom}),
+		Baseline:     dims.Baseline + bottom,
+		LastBaseline: dims.lastBaseline() + bottom,
+	}
+}
+
+// Widget returns a Widget that lays out w with the inset.
+func (in Inset) Widget(w Widget) Widget {
+	return func(gtx Context) Dimensions {
+		return in.Layout(gtx, w)
 	}
 }
 
@@ -180,6 +262,48 @@ func UniformInset(v unit.Dp) Inset {
 	return Inset{Top: v, Right: v, Bottom: v, Left: v}
 }
 
+// Add returns the Inset with each edge the sum of the edges of in and
+// other. Laying out with the sum is equivalent to nesting the two
+// insets, except that only a single offset is applied.
+func (in Inset) Add(other Inset) Inset {
+	return Inset{
+		Top:    in.Top + other.Top,
+		Bottom: in.Bottom + other.Bottom,
+		Left:   in.Left + other.Left,
+		Right:  in.Right + other.Right,
+	}
+}
+
+// BaselineInset adds space above a widget so that its baseline is Top
+// from the top edge. A widget whose ascent exceeds Top is not offset, and
+// a widget without a baseline is inset by Top.
+type BaselineInset struct {
+	Top unit.Dp
+}
+
+func (b BaselineInset) Layout(gtx Context, w Widget) Dimensions {
+	top := gtx.Dp(b.Top)
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := macro.Stop()
+	off := top
+	if dims.Baseline != 0 {
+		off -= dims.Size.Y - dims.Baseline
+	}
+	if off < 0 {
+		off = 0
+	}
+	trans := op.Offset(image.Pt(0, off)).Push(gtx.Ops)
+	call.Add(gtx.Ops)
+	trans.Pop()
+	sz := gtx.Constraints.Constrain(dims.Size.Add(image.Pt(0, off)))
+	return Dimensions{
+		Size:         sz,
+		Baseline:     dims.Baseline + sz.Y - dims.Size.Y - off,
+		LastBaseline: dims.lastBaseline() + sz.Y - dims.Size.Y - off,
+	}
+}
+
 // Layout a widget according to the direction.
 // The widget is called with the context constraints minimum cleared.
 func (d Direction) Layout(gtx Context, w Widget) Dimensions {
@@ -208,8 +332,16 @@ func (d Direction) Layout(gtx Context, w Widget) Dimensions {
 	call.Add(gtx.Ops)
 
 	return Dimensions{
-		Size:     sz,
-		Baseline: dims.Baseline + sz.Y - dims.Size.Y - p.Y,
+		Size:         sz,
+		Baseline:     dims.Baseline + sz.Y - dims.Size.Y - p.Y,
+		LastBaseline: dims.lastBaseline() + sz.Y - dims.Size.Y - p.Y,
+	}
+}
+
+// Widget returns a Widget that lays out w according to the direction.
+func (d Direction) Widget(w Widget) Widget {
+	return func(gtx Context) Dimensions {
+		return d.Layout(gtx, w)
 	}
 }
 
@@ -234,6 +366,66 @@ func (d Direction) Position(widget, bounds image.Point) image.Point {
 	return p
 }
 
+// FlipHorizontal returns the direction mirrored along the vertical
+// axis, for example NE for NW. N, S and Center are unchanged.
+func (d Direction) FlipHorizontal() Direction {
+	switch d {
+	case NW:
+		return NE
+	case NE:
+		return NW
+	case E:
+		return W
+	case W:
+		return E
+	case SE:
+		return SW
+	case SW:
+		return SE
+	default:
+		return d
+	}
+}
+
+// FlipVertical returns the direction mirrored along the horizontal
+// axis, for example SW for NW. E, W and Center are unchanged.
+func (d Direction) FlipVertical() Direction {
+	switch d {
+	case NW:
+		return SW
+	case SW:
+		return NW
+	case N:
+		return S
+	case S:
+		return N
+	case NE:
+		return SE
+	case SE:
+		return NE
+	default:
+		return d
+	}
+}
+
+// Position calculates the cross axis offset of a child of size child
+// within a space of size bounds, according to the alignment.
+// The baselines are measured from the start of the cross axis and are
+// only used by Baseline and LastBaseline, which align the child baseline
+// with the container baseline.
+func (a Alignment) Position(child, bounds, childBaseline, baseline int) int {
+	switch a {
+	case End:
+		return bounds - child
+	case Middle:
+		return (bounds - child) / 2
+	case Baseline, LastBaseline:
+		return baseline - childBaseline
+	default:
+		return 0
+	}
+}
+
 // Spacer adds space between widgets.
 type Spacer struct {
 	Width, Height unit.Dp
@@ -248,6 +440,224 @@ func (s Spacer) Layout(gtx Context) Dimensions {
 	}
 }
 
+// Divider draws a rule across a layout, such as a separator between
+// menu or list items.
+type Divider struct {
+	// Axis is the main axis of the layout the divider separates. The
+	// rule spans the maximum cross axis constraint.
+	Axis Axis
+	// Thickness is the main axis size of the rule. It is rounded to
+	// whole pixels, and the rule is at least 1 pixel thick for a positive
+	// Thickness, so hairlines stay crisp at fractional densities.
+	Thickness unit.Dp
+	// Color is the color of the rule.
+	Color color.NRGBA
+}
+
+func (d Divider) Layout(gtx Context) Dimensions {
+	_, crossMax := d.Axis.crossConstraint(gtx.Constraints)
+	t := gtx.Dp(d.Thickness)
+	if t == 0 && d.Thickness > 0 {
+		t = 1
+	}
+	sz := d.Axis.Convert(image.Pt(t, crossMax))
+	sz = gtx.Constraints.Constrain(sz)
+	paint.FillShape(gtx.Ops, d.Color, clip.Rect{Max: sz}.Op())
+	return Dimensions{Size: sz}
+}
+
+// Debug outlines the bounds of a widget when DebugBounds is set.
+type Debug struct {
+	// Color is the color of the outline.
+	Color color.NRGBA
+}
+
+// Layout a widget and, if DebugBounds is set, draw a 1 pixel outline
+// inside its dimensions on top of it.
+func (d Debug) Layout(gtx Context, w Widget) Dimensions {
+	dims := w(gtx)
+	if DebugBounds {
+		d.outline(gtx, dims.Size)
+	}
+	return dims
+}
+
+// outline draws a 1 pixel outline inside a rectangle of size sz.
+func (d Debug) outline(gtx Context, sz image.Point) {
+	for _, r := range [...]image.Rectangle{
+		image.Rect(0, 0, sz.X, 1),
+		image.Rect(0, sz.Y-1, sz.X, sz.Y),
+		image.Rect(0, 0, 1, sz.Y),
+		image.Rect(sz.X-1, 0, sz.X, sz.Y),
+	} {
+		paint.FillShape(gtx.Ops, d.Color, clip.Rect(r).Op())
+	}
+}
+
+// RoundedClip clips a widget to a rectangle of its size with rounded
+// corners. The radius is limited to half the smaller dimension of the
+// widget.
+type RoundedClip struct {
+	Radius unit.Dp
+}
+
+func (r RoundedClip) Layout(gtx Context, w Widget) Dimensions {
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := macro.Stop()
+	rad := gtx.Dp(r.Radius)
+	if m := dims.Size.X / 2; rad > m {
+		rad = m
+	}
+	if m := dims.Size.Y / 2; rad > m {
+		rad = m
+	}
+	defer clip.UniformRRect(image.Rectangle{Max: dims.Size}, rad).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// Constrained limits the maximum size of a widget and positions it within
+// the available space, such as for a readable column of text. A zero
+// MaxWidth or MaxHeight doesn't limit that axis.
+type Constrained struct {
+	MaxWidth, MaxHeight unit.Dp
+	// Align positions the widget within the constraints.
+	Align Direction
+}
+
+func (c Constrained) Layout(gtx Context, w Widget) Dimensions {
+	return c.Align.Layout(gtx, func(gtx Context) Dimensions {
+		cs := gtx.Constraints
+		if max := gtx.Dp(c.MaxWidth); max > 0 && max < cs.Max.X {
+			cs.Max.X = max
+		}
+		if max := gtx.Dp(c.MaxHeight); max > 0 && max < cs.Max.Y {
+			cs.Max.Y = max
+		}
+		cs.Min = cs.Constrain(cs.Min)
+		gtx.Constraints = cs
+		return w(gtx)
+	})
+}
+
+// MinSize lays out a widget with its minimum constraints enlarged to at
+// least Width and Height, within the maximum constraints. The dimensions
+// of a widget smaller than the enlarged minimum are padded out to it.
+type MinSize struct {
+	Width, Height unit.Dp
+}
+
+func (m MinSize) Layout(gtx Context, w Widget) Dimensions {
+	cs := gtx.Constraints
+	if min := gtx.Dp(m.Width); min > cs.Min.X {
+		cs.Min.X = min
+	}
+	if min := gtx.Dp(m.Height); min > cs.Min.Y {
+		cs.Min.Y = min
+	}
+	cs.Min = cs.Constrain(cs.Min)
+	gtx.Constraints = cs
+	dims := w(gtx)
+	sz := dims.Size
+	if sz.X < cs.Min.X {
+		sz.X = cs.Min.X
+	}
+	if sz.Y < cs.Min.Y {
+		sz.Y = cs.Min.Y
+	}
+	return Dimensions{
+		Size:         sz,
+		Baseline:     dims.Baseline + sz.Y - dims.Size.Y,
+		LastBaseline: dims.lastBaseline() + sz.Y - dims.Size.Y,
+	}
+}
+
+// Snap rounds the size of a widget up to a multiple of a grid size,
+// within the maximum constraints, so that adjacent widgets align on the
+// grid.
+type Snap struct {
+	// Grid is the grid size in pixels. Grid sizes less than 2 leave the
+	// size unchanged.
+	Grid int
+}
+
+func (s Snap) Layout(gtx Context, w Widget) Dimensions {
+	dims := w(gtx)
+	if s.Grid < 2 {
+		return dims
+	}
+	sz := dims.Size
+	sz.X = (sz.X + s.Grid - 1) / s.Grid * s.Grid
+	sz.Y = (sz.Y + s.Grid - 1) / s.Grid * s.Grid
+	sz = gtx.Constraints.Constrain(sz)
+	return Dimensions{
+		Size:         sz,
+		Baseline:     dims.Baseline + sz.Y - dims.Size.Y,
+		LastBaseline: dims.lastBaseline() + sz.Y - dims.Size.Y,
+	}
+}
+
+// AspectRatio lays out a widget with exact constraints of a size with
+// the ratio of width to height Ratio. If one axis is tight, as for a
+// Flexed child, the size fills that axis and the other axis follows from
+// the ratio. Otherwise the size is the largest that fits the maximum
+// constraints. A Ratio of zero or less leaves the constraints unchanged.
+type AspectRatio struct {
+	Ratio float32
+}
+
+func (a AspectRatio) Layout(gtx Context, w Widget) Dimensions {
+	if a.Ratio <= 0 {
+		return w(gtx)
+	}
+	cs := gtx.Constraints
+	var sz image.Point
+	switch {
+	case cs.Min.X == cs.Max.X:
+		sz.X = cs.Max.X
+		sz.Y = int(float32(sz.X)/a.Ratio + .5)
+	case cs.Min.Y == cs.Max.Y:
+		sz.Y = cs.Max.Y
+		sz.X = int(float32(sz.Y)*a.Ratio + .5)
+	default:
+		sz.X = cs.Max.X
+		sz.Y = int(float32(sz.X)/a.Ratio + .5)
+		if sz.Y > cs.Max.Y {
+			sz.Y = cs.Max.Y
+			sz.X = int(float32(sz.Y)*a.Ratio + .5)
+		}
+	}
+	gtx.Constraints = Exact(cs.Constrain(sz))
+	return w(gtx)
+}
+
+// Square lays out a widget with exact square constraints, positioned
+// within the available space, for avatars, icons and color swatches.
+type Square struct {
+	// Size is the side of the square. If zero, the square is the largest
+	// that fits the maximum constraints. The side is limited to the
+	// smaller maximum constraint.
+	Size unit.Dp
+	// Align positions the square within the constraints.
+	Align Direction
+}
+
+func (s Square) Layout(gtx Context, w Widget) Dimensions {
+	max := gtx.Constraints.Max
+	side := max.X
+	if max.Y < side {
+		side = max.Y
+	}
+	if sz := gtx.Dp(s.Size); sz > 0 && sz < side {
+		side = sz
+	}
+	return s.Align.Layout(gtx, func(gtx Context) Dimensions {
+		gtx.Constraints = Exact(image.Pt(side, side))
+		return w(gtx)
+	})
+}
+
 func (a Alignment) String() string {
 	switch a {
 	case Start:
@@ -258,6 +668,8 @@ func (a Alignment) String() string {
 		return "Middle"
 	case Baseline:
 		return "Baseline"
+	case LastBaseline:
+		return "LastBaseline"
 	default:
 		panic("unreachable")
 	}
@@ -342,3 +754,9 @@ func (d Direction) String() string {
 		panic("unreachable")
 	}
 }
+
+// String returns the constraints in the form "W[min,max] H[min,max]".
+func (c Constraints) String() string {
+	return "W[" + strconv.Itoa(c.Min.X) + "," + strconv.Itoa(c.Max.X) + "] " +
+		"H[" + strconv.Itoa(c.Min.Y) + "," + strconv.Itoa(c.Max.Y) + "]"
+}
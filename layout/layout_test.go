@@ -4,9 +4,18 @@ package layout
 
 import (
 	"image"
+	"math"
+	"reflect"
 	"testing"
 
+	"gioui.org/f32"
+	"gioui.org/internal/ops"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/io/router"
+	"gioui.org/io/system"
 	"gioui.org/op"
+	"gioui.org/unit"
 )
 
 func TestStack(t *testing.T) {
@@ -142,3 +151,1359 @@ func TestConstraints(t *testing.T) {
 		})
 	}
 }
+
+func TestAlignmentPosition(t *testing.T) {
+	for _, tc := range []struct {
+		align Alignment
+		exp   int
+	}{
+		{Start, 0},
+		{End, 60},
+		{Middle, 30},
+		{Baseline, 15},
+		{LastBaseline, 15},
+	} {
+		t.Run(tc.align.String(), func(t *testing.T) {
+			if got := tc.align.Position(40, 100, 25, 40); got != tc.exp {
+				t.Errorf("got %d; expected %d", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestInset(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		cs    Constraints
+		inset Inset
+		child image.Point
+		min   image.Point
+		max   image.Point
+		exp   Dimensions
+	}{
+		{
+			name:  "shrink",
+			cs:    Constraints{Max: image.Pt(100, 100)},
+			inset: Inset{Top: 10, Bottom: 20, Left: 5, Right: 15},
+			child: image.Pt(50, 50),
+			max:   image.Pt(80, 70),
+			exp:   Dimensions{Size: image.Pt(70, 80), Baseline: 20, LastBaseline: 20},
+		},
+		{
+			name:  "clamp min",
+			cs:    Exact(image.Pt(100, 100)),
+			inset: UniformInset(10),
+			child: image.Pt(80, 80),
+			min:   image.Pt(80, 80),
+			max:   image.Pt(80, 80),
+			exp:   Dimensions{Size: image.Pt(100, 100), Baseline: 10, LastBaseline: 10},
+		},
+		{
+			name:  "clamp max",
+			cs:    Constraints{Max: image.Pt(10, 10)},
+			inset: UniformInset(10),
+			exp:   Dimensions{Size: image.Pt(0, 0)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gtx := Context{
+				Ops:         new(op.Ops),
+				Constraints: tc.cs,
+			}
+			var cs Constraints
+			dims := tc.inset.Layout(gtx, func(gtx Context) Dimensions {
+				cs = gtx.Constraints
+				return Dimensions{Size: tc.child}
+			})
+			if got, exp := cs, (Constraints{Min: tc.min, Max: tc.max}); got != exp {
+				t.Errorf("got constraints %v; expected %v", got, exp)
+			}
+			if got := dims; got != tc.exp {
+				t.Errorf("got dimensions %v; expected %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestDirectionPosition(t *testing.T) {
+	widget, bounds := image.Pt(20, 10), image.Pt(100, 50)
+	for _, tc := range []struct {
+		dir Direction
+		exp image.Point
+	}{
+		{NW, image.Pt(0, 0)},
+		{N, image.Pt(40, 0)},
+		{NE, image.Pt(80, 0)},
+		{E, image.Pt(80, 20)},
+		{SE, image.Pt(80, 40)},
+		{S, image.Pt(40, 40)},
+		{SW, image.Pt(0, 40)},
+		{W, image.Pt(0, 20)},
+		{Center, image.Pt(40, 20)},
+	} {
+		t.Run(tc.dir.String(), func(t *testing.T) {
+			if got := tc.dir.Position(widget, bounds); got != tc.exp {
+				t.Errorf("got %v; expected %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestFork(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(100, 100)),
+	}
+	var calls [2]op.CallOp
+	var dims [2]Dimensions
+	done := make(chan struct{})
+	for i := range calls {
+		fgtx, macro := gtx.Fork()
+		if fgtx.Ops == gtx.Ops {
+			t.Fatal("fork shares operation list with parent")
+		}
+		go func(i int) {
+			dims[i] = Inset{Left: unit.Dp(i)}.Layout(fgtx, func(gtx Context) Dimensions {
+				return Dimensions{Size: gtx.Constraints.Min}
+			})
+			calls[i] = macro.Stop()
+			done <- struct{}{}
+		}(i)
+	}
+	for range calls {
+		<-done
+	}
+	for i, c := range calls {
+		c.Add(gtx.Ops)
+		if got, exp := dims[i].Size, gtx.Constraints.Max; got != exp {
+			t.Errorf("fork %d: got size %v; expected %v", i, got, exp)
+		}
+	}
+}
+
+func TestAxisConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		axis Axis
+		exp  Constraints
+	}{
+		{Horizontal, Constraints{Min: image.Pt(1, 3), Max: image.Pt(2, 4)}},
+		{Vertical, Constraints{Min: image.Pt(3, 1), Max: image.Pt(4, 2)}},
+	} {
+		t.Run(tc.axis.String(), func(t *testing.T) {
+			if got := AxisConstraints(tc.axis, 1, 2, 3, 4); got != tc.exp {
+				t.Errorf("got %v; expected %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestSpacingString(t *testing.T) {
+	for s, exp := range []string{"SpaceEnd", "SpaceStart", "SpaceSides", "SpaceAround", "SpaceBetween", "SpaceEvenly"} {
+		if got := Spacing(s).String(); got != exp {
+			t.Errorf("Spacing(%d).String() = %q; expected %q", s, got, exp)
+		}
+	}
+}
+
+func TestMinSize(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	min := MinSize{Width: 48, Height: 48}
+	for _, tc := range []struct {
+		name  string
+		child image.Point
+		exp   image.Point
+	}{
+		{"smaller", image.Pt(20, 10), image.Pt(48, 48)},
+		{"larger", image.Pt(60, 50), image.Pt(60, 50)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dims := min.Layout(gtx, func(gtx Context) Dimensions {
+				if got, exp := gtx.Constraints.Min, image.Pt(48, 48); got != exp {
+					t.Errorf("got minimum %v; expected %v", got, exp)
+				}
+				return Dimensions{Size: tc.child}
+			})
+			if got := dims.Size; got != tc.exp {
+				t.Errorf("got %v; expected %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestInsetBaseline(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	// Baselines are measured from the bottom, so only the bottom
+	// inset affects them.
+	const childBaseline = 7
+	dims := Inset{Top: 10, Bottom: 5}.Layout(gtx, func(gtx Context) Dimensions {
+		return Dimensions{Size: image.Pt(20, 20), Baseline: childBaseline, LastBaseline: 3}
+	})
+	if got, exp := dims.Baseline, childBaseline+5; got != exp {
+		t.Errorf("got baseline %d; expected %d", got, exp)
+	}
+	if got, exp := dims.LastBaseline, 3+5; got != exp {
+		t.Errorf("got last baseline %d; expected %d", got, exp)
+	}
+	if got, exp := dims.Size.Y-dims.Baseline, 10+20-childBaseline; got != exp {
+		t.Errorf("got baseline %d from the top; expected %d", got, exp)
+	}
+	// A zero inset reports Baseline for a missing LastBaseline, as other
+	// insets do.
+	for _, in := range []Inset{{}, {Left: 1}} {
+		dims := in.Layout(gtx, func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(10, 10), Baseline: 3}
+		})
+		if dims.LastBaseline != 3 {
+			t.Errorf("%+v: got last baseline %d; expected 3", in, dims.LastBaseline)
+		}
+	}
+}
+
+func TestLastBaseline(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	tests := []struct {
+		child Dimensions
+		last  int
+	}{
+		{Dimensions{Size: image.Pt(20, 20), Baseline: 12, LastBaseline: 3}, 3},
+		// A child without LastBaseline has its Baseline reported.
+		{Dimensions{Size: image.Pt(20, 20), Baseline: 12}, 12},
+	}
+	for i, test := range tests {
+		w := func(gtx Context) Dimensions {
+			return test.child
+		}
+		layouts := map[string]Dimensions{
+			"Flex":  Flex{}.Layout(gtx, Rigid(w)),
+			"Row":   Row{}.Layout(gtx, w),
+			"Stack": Stack{}.Layout(gtx, Stacked(w)),
+		}
+		for name, dims := range layouts {
+			if dims.Baseline != test.child.Baseline {
+				t.Errorf("%d: %s: got baseline %d; expected %d", i, name, dims.Baseline, test.child.Baseline)
+			}
+			if dims.LastBaseline != test.last {
+				t.Errorf("%d: %s: got last baseline %d; expected %d", i, name, dims.LastBaseline, test.last)
+			}
+		}
+	}
+}
+
+func TestAlignLastBaseline(t *testing.T) {
+	// The first child has its first and last baselines 8 and 17 from the
+	// top, the second child its single baseline 8 from the top.
+	first := func(gtx Context) Dimensions {
+		return Dimensions{Size: image.Pt(20, 20), Baseline: 12, LastBaseline: 3}
+	}
+	tests := []struct {
+		align   Alignment
+		presses int
+	}{
+		// Aligned at the top.
+		{Baseline, 0},
+		// Moved down 9 pixels, covering the press.
+		{LastBaseline, 1},
+	}
+	for _, test := range tests {
+		layouts := map[string]func(gtx Context, second Widget){
+			"Flex": func(gtx Context, second Widget) {
+				Flex{Alignment: test.align}.Layout(gtx, Rigid(first), Rigid(second))
+			},
+			"Row": func(gtx Context, second Widget) {
+				Row{Alignment: test.align}.Layout(gtx, first, second)
+			},
+		}
+		for name, layout := range layouts {
+			r := new(router.Router)
+			gtx := Context{
+				Ops:         new(op.Ops),
+				Constraints: Constraints{Max: image.Pt(100, 100)},
+				Queue:       r,
+			}
+			tag := new(int)
+			layout(gtx, func(gtx Context) Dimensions {
+				gtx.InputArea(image.Pt(20, 10), tag, pointer.Press)
+				return Dimensions{Size: image.Pt(20, 10), Baseline: 2}
+			})
+			r.Frame(gtx.Ops)
+			p := f32.Pt(30, 15)
+			r.Queue(
+				pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: p},
+				pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: p},
+			)
+			n := 0
+			for _, e := range gtx.Events(tag) {
+				if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+					n++
+				}
+			}
+			if n != test.presses {
+				t.Errorf("%s %v: got %d presses; expected %d", name, test.align, n, test.presses)
+			}
+		}
+	}
+}
+
+func TestBackground(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	fg := Dimensions{Size: image.Pt(30, 20), Baseline: 5}
+	var bgcs Constraints
+	dims := Background{}.Layout(gtx,
+		func(gtx Context) Dimensions {
+			bgcs = gtx.Constraints
+			return Dimensions{Size: image.Pt(50, 50)}
+		},
+		func(gtx Context) Dimensions {
+			return fg
+		},
+	)
+	if got, exp := bgcs, Exact(fg.Size); got != exp {
+		t.Errorf("got background constraints %v; expected %v", got, exp)
+	}
+	if dims != fg {
+		t.Errorf("got dimensions %v; expected %v", dims, fg)
+	}
+}
+
+func TestConstraintsExact(t *testing.T) {
+	if cs := Exact(image.Pt(10, 20)); !cs.Exact() {
+		t.Errorf("%v is not exact", cs)
+	}
+	if cs := (Constraints{Min: image.Pt(10, 20), Max: image.Pt(10, 30)}); cs.Exact() {
+		t.Errorf("%v is exact", cs)
+	}
+}
+
+func TestStackZIndex(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	// Each child marks its drawing with id InvalidateOps.
+	child := func(id int) Widget {
+		return func(gtx Context) Dimensions {
+			for i := 0; i < id; i++ {
+				op.InvalidateOp{}.Add(gtx.Ops)
+			}
+			return Dimensions{Size: image.Pt(10, 10)}
+		}
+	}
+	Stack{}.Layout(gtx,
+		Stacked(child(1)).ZIndex(2),
+		Stacked(child(2)),
+		Expanded(child(3)).ZIndex(-1),
+		Stacked(child(4)),
+		Stacked(child(5)).ZIndex(2),
+	)
+	// The children are delimited by the operations positioning them.
+	var order []int
+	run := 0
+	for _, t := range decodeOps(gtx.Ops) {
+		switch {
+		case t == ops.TypeInvalidate:
+			run++
+		case run > 0:
+			order = append(order, run)
+			run = 0
+		}
+	}
+	if exp := []int{3, 2, 4, 1, 5}; !reflect.DeepEqual(order, exp) {
+		t.Errorf("got drawing order %v; expected %v", order, exp)
+	}
+}
+
+func TestInsetFractionalDensity(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Metric:      unit.Metric{PxPerDp: 1.5},
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	// Every edge is rounded the same way, so opposite edges of a
+	// uniform inset are always equal.
+	const edge = 10.5 // 15.75 pixels.
+	px := gtx.Dp(edge)
+	var max image.Point
+	UniformInset(edge).Layout(gtx, func(gtx Context) Dimensions {
+		max = gtx.Constraints.Max
+		return Dimensions{Size: max}
+	})
+	if got, exp := max, image.Pt(100-2*px, 100-2*px); got != exp {
+		t.Errorf("got %v; expected %v", got, exp)
+	}
+}
+
+func TestFlexMin(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		width int
+		exp   [3]int
+	}{
+		{"distribute", 100, [3]int{50, 30, 20}},
+		{"minimums", 60, [3]int{30, 10, 20}},
+		{"shrink", 30, [3]int{15, 5, 10}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gtx := Context{
+				Ops:         new(op.Ops),
+				Constraints: Exact(image.Pt(tc.width, 10)),
+			}
+			var sizes [3]int
+			child := func(i int) Widget {
+				return func(gtx Context) Dimensions {
+					sizes[i] = gtx.Constraints.Min.X
+					return Dimensions{Size: gtx.Constraints.Min}
+				}
+			}
+			Flex{}.Layout(gtx,
+				Flexed(1, child(0)).Min(30),
+				Flexed(1, child(1)).Min(10),
+				Flexed(0, child(2)).Min(20),
+			)
+			if sizes != tc.exp {
+				t.Errorf("got sizes %v; expected %v", sizes, tc.exp)
+			}
+		})
+	}
+}
+
+func TestDirectionFlip(t *testing.T) {
+	for _, tc := range []struct {
+		dir, horiz, vert Direction
+	}{
+		{NW, NE, SW},
+		{N, N, S},
+		{NE, NW, SE},
+		{E, W, E},
+		{SE, SW, NE},
+		{S, S, N},
+		{SW, SE, NW},
+		{W, E, W},
+		{Center, Center, Center},
+	} {
+		t.Run(tc.dir.String(), func(t *testing.T) {
+			if got := tc.dir.FlipHorizontal(); got != tc.horiz {
+				t.Errorf("FlipHorizontal: got %v; expected %v", got, tc.horiz)
+			}
+			if got := tc.dir.FlipVertical(); got != tc.vert {
+				t.Errorf("FlipVertical: got %v; expected %v", got, tc.vert)
+			}
+		})
+	}
+}
+
+func TestDivider(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Metric:      unit.Metric{PxPerDp: 2},
+		Constraints: Constraints{Max: image.Pt(100, 50)},
+	}
+	for _, tc := range []struct {
+		axis Axis
+		exp  image.Point
+	}{
+		{Vertical, image.Pt(100, 2)},
+		{Horizontal, image.Pt(2, 50)},
+	} {
+		t.Run(tc.axis.String(), func(t *testing.T) {
+			dims := Divider{Axis: tc.axis, Thickness: 1}.Layout(gtx)
+			if got := dims.Size; got != tc.exp {
+				t.Errorf("got %v; expected %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestDividerHairline(t *testing.T) {
+	for _, tc := range []struct {
+		density   float32
+		thickness unit.Dp
+		exp       int
+	}{
+		{1.25, 1, 1},
+		{1.5, 1, 2},
+		{1, .25, 1},
+		{1, 0, 0},
+	} {
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Metric:      unit.Metric{PxPerDp: tc.density},
+			Constraints: Constraints{Max: image.Pt(100, 50)},
+		}
+		dims := Divider{Axis: Vertical, Thickness: tc.thickness}.Layout(gtx)
+		if got := dims.Size.Y; got != tc.exp {
+			t.Errorf("%vdp at density %v: got %dpx; expected %dpx", tc.thickness, tc.density, got, tc.exp)
+		}
+	}
+}
+
+func TestUnbounded(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(100, 50)),
+	}
+	for _, tc := range []struct {
+		axis Axis
+		exp  Constraints
+	}{
+		{Horizontal, Constraints{Min: image.Pt(0, 50), Max: image.Pt(Inf, 50)}},
+		{Vertical, Constraints{Min: image.Pt(100, 0), Max: image.Pt(100, Inf)}},
+	} {
+		t.Run(tc.axis.String(), func(t *testing.T) {
+			gtx.Unbounded(tc.axis, func(gtx Context) Dimensions {
+				if got := gtx.Constraints; got != tc.exp {
+					t.Errorf("got %v; expected %v", got, tc.exp)
+				}
+				return Dimensions{}
+			})
+		})
+	}
+}
+
+func TestWidget(t *testing.T) {
+	w := Center.Widget(UniformInset(10).Widget(func(gtx Context) Dimensions {
+		return Dimensions{Size: gtx.Constraints.Max}
+	}))
+	// The composed widget must use the constraints it is called with.
+	for _, max := range []image.Point{image.Pt(100, 100), image.Pt(50, 40)} {
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Constraints{Max: max},
+		}
+		if got := w(gtx).Size; got != max {
+			t.Errorf("got %v; expected %v", got, max)
+		}
+	}
+}
+
+func TestConstraintsUnbounded(t *testing.T) {
+	cs := Constraints{Min: image.Pt(10, 20), Max: image.Pt(30, 40)}
+	// A horizontal scroller nested in a vertical one.
+	outer := cs.Unbounded(Vertical)
+	if exp := (Constraints{Min: image.Pt(10, 0), Max: image.Pt(30, Inf)}); outer != exp {
+		t.Errorf("got %v; expected %v", outer, exp)
+	}
+	inner := outer.Unbounded(Horizontal)
+	if exp := (Constraints{Max: image.Pt(Inf, Inf)}); inner != exp {
+		t.Errorf("got %v; expected %v", inner, exp)
+	}
+}
+
+func TestSnap(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(200, 100)},
+	}
+	for _, tc := range []struct {
+		child, exp image.Point
+	}{
+		{image.Pt(103, 16), image.Pt(104, 16)},
+		{image.Pt(0, 1), image.Pt(0, 8)},
+		{image.Pt(199, 99), image.Pt(200, 100)},
+	} {
+		dims := Snap{Grid: 8}.Layout(gtx, func(gtx Context) Dimensions {
+			return Dimensions{Size: tc.child}
+		})
+		if got := dims.Size; got != tc.exp {
+			t.Errorf("snapping %v: got %v; expected %v", tc.child, got, tc.exp)
+		}
+	}
+}
+
+func TestStackChildConstraints(t *testing.T) {
+	gtx := Context{
+		Ops: new(op.Ops),
+		Constraints: Constraints{
+			Min: image.Pt(10, 10),
+			Max: image.Pt(100, 100),
+		},
+	}
+	var stacked, expanded Constraints
+	dims := Stack{}.Layout(gtx,
+		Expanded(func(gtx Context) Dimensions {
+			expanded = gtx.Constraints
+			return Dimensions{Size: gtx.Constraints.Min}
+		}),
+		Stacked(func(gtx Context) Dimensions {
+			stacked = gtx.Constraints
+			return Dimensions{Size: image.Pt(40, 30)}
+		}),
+		Stacked(func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(20, 50)}
+		}),
+	)
+	if exp := (Constraints{Max: gtx.Constraints.Max}); stacked != exp {
+		t.Errorf("Stacked: got %v; expected %v", stacked, exp)
+	}
+	if exp := (Constraints{Min: image.Pt(40, 50), Max: gtx.Constraints.Max}); expanded != exp {
+		t.Errorf("Expanded: got %v; expected %v", expanded, exp)
+	}
+	if got, exp := dims.Size, image.Pt(40, 50); got != exp {
+		t.Errorf("got size %v; expected %v", got, exp)
+	}
+}
+
+func TestBaselineInset(t *testing.T) {
+	gtx := Context{
+		Ops: new(op.Ops),
+		Constraints: Constraints{
+			Max: image.Pt(100, 100),
+		},
+	}
+	tests := []struct {
+		child Dimensions
+		dims  Dimensions
+	}{
+		// Ascent 6, baseline moved to 10 from the top.
+		{Dimensions{Size: image.Pt(20, 8), Baseline: 2}, Dimensions{Size: image.Pt(20, 12), Baseline: 2, LastBaseline: 2}},
+		// Ascent larger than Top.
+		{Dimensions{Size: image.Pt(20, 16), Baseline: 2}, Dimensions{Size: image.Pt(20, 16), Baseline: 2, LastBaseline: 2}},
+		// No baseline.
+		{Dimensions{Size: image.Pt(20, 8)}, Dimensions{Size: image.Pt(20, 18)}},
+	}
+	for i, test := range tests {
+		dims := BaselineInset{Top: 10}.Layout(gtx, func(gtx Context) Dimensions {
+			return test.child
+		})
+		if dims != test.dims {
+			t.Errorf("%d: got %+v; expected %+v", i, dims, test.dims)
+		}
+	}
+}
+
+func TestConstraintsString(t *testing.T) {
+	cs := Constraints{Min: image.Pt(0, 10), Max: image.Pt(100, 200)}
+	if got, exp := cs.String(), "W[0,100] H[10,200]"; got != exp {
+		t.Errorf("got %q; expected %q", got, exp)
+	}
+}
+
+func TestRoundedClip(t *testing.T) {
+	gtx := Context{
+		Ops: new(op.Ops),
+		Constraints: Constraints{
+			Max: image.Pt(100, 100),
+		},
+	}
+	exp := Dimensions{Size: image.Pt(40, 10), Baseline: 3}
+	dims := RoundedClip{Radius: 20}.Layout(gtx, func(gtx Context) Dimensions {
+		return exp
+	})
+	if dims != exp {
+		t.Errorf("got %+v; expected %+v", dims, exp)
+	}
+}
+
+func TestClippedOffset(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	exp := Dimensions{Size: image.Pt(100, 300), Baseline: 5}
+	dims := gtx.ClippedOffset(image.Rect(0, 0, 100, 100), image.Pt(0, -50), func(gtx Context) Dimensions {
+		if got := gtx.Constraints.Max; got != image.Pt(100, 100) {
+			t.Errorf("got max constraint %v", got)
+		}
+		return exp
+	})
+	if dims != exp {
+		t.Errorf("got %+v; expected %+v", dims, exp)
+	}
+}
+
+func TestEqualSize(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	var got []Constraints
+	widget := func(sz image.Point) Widget {
+		return func(gtx Context) Dimensions {
+			got = append(got, gtx.Constraints)
+			op.InvalidateOp{}.Add(gtx.Ops)
+			return Dimensions{Size: gtx.Constraints.Constrain(sz)}
+		}
+	}
+	widgets := []Widget{widget(image.Pt(10, 5)), widget(image.Pt(30, 10))}
+	dims, w := gtx.EqualSize(Horizontal, widgets)
+	if exp := []Dimensions{{Size: image.Pt(10, 5)}, {Size: image.Pt(30, 10)}}; !reflect.DeepEqual(dims, exp) {
+		t.Errorf("got measured %v; expected %v", dims, exp)
+	}
+	if n := countOps(gtx.Ops); n != 0 {
+		t.Errorf("measurement added %d operations", n)
+	}
+	got = nil
+	if sz, exp := w(gtx).Size, image.Pt(60, 10); sz != exp {
+		t.Errorf("got size %v; expected %v", sz, exp)
+	}
+	exp := Constraints{Min: image.Pt(30, 0), Max: image.Pt(30, 100)}
+	for i, cs := range got {
+		if cs != exp {
+			t.Errorf("%d: got constraints %v; expected %v", i, cs, exp)
+		}
+	}
+	// The equal size is limited to fit.
+	gtx.Constraints.Max.X = 40
+	_, w = gtx.EqualSize(Horizontal, widgets)
+	if sz, exp := w(gtx).Size, image.Pt(40, 10); sz != exp {
+		t.Errorf("got limited size %v; expected %v", sz, exp)
+	}
+}
+
+func TestAspectRatio(t *testing.T) {
+	fill := func(gtx Context) Dimensions {
+		return Dimensions{Size: gtx.Constraints.Min}
+	}
+	tests := []struct {
+		cs  Constraints
+		exp image.Point
+	}{
+		{Constraints{Max: image.Pt(100, 100)}, image.Pt(100, 50)},
+		{Constraints{Max: image.Pt(100, 20)}, image.Pt(40, 20)},
+		// Tight width, as for a horizontal Flexed child.
+		{Constraints{Min: image.Pt(60, 0), Max: image.Pt(60, 100)}, image.Pt(60, 30)},
+		// Tight height.
+		{Constraints{Min: image.Pt(0, 10), Max: image.Pt(100, 10)}, image.Pt(20, 10)},
+		// Unbounded main axis.
+		{Constraints{Max: image.Pt(100, Inf)}, image.Pt(100, 50)},
+	}
+	for i, test := range tests {
+		gtx := Context{Ops: new(op.Ops), Constraints: test.cs}
+		if got := (AspectRatio{Ratio: 2}).Layout(gtx, fill).Size; got != test.exp {
+			t.Errorf("%d: got %v; expected %v", i, got, test.exp)
+		}
+	}
+	// A Flexed child receives a tight width and computes its height.
+	gtx := Context{Ops: new(op.Ops), Constraints: Constraints{Max: image.Pt(100, 100)}}
+	var flexed Dimensions
+	Flex{}.Layout(gtx,
+		Rigid(func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(40, 10)}
+		}),
+		Flexed(1, func(gtx Context) Dimensions {
+			flexed = AspectRatio{Ratio: 2}.Layout(gtx, fill)
+			return flexed
+		}),
+	)
+	if exp := image.Pt(60, 30); flexed.Size != exp {
+		t.Errorf("flexed: got %v; expected %v", flexed.Size, exp)
+	}
+}
+
+func TestInputArea(t *testing.T) {
+	r := new(router.Router)
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+		Queue:       r,
+	}
+	below, above := new(int), new(int)
+	gtx.InputArea(image.Pt(100, 100), below, pointer.Press)
+	gtx.Offset(image.Pt(50, 0), func(gtx Context) Dimensions {
+		gtx.InputArea(image.Pt(50, 50), above, pointer.Press)
+		return Dimensions{}
+	})
+	r.Frame(gtx.Ops)
+	for _, p := range []f32.Point{f32.Pt(10, 10), f32.Pt(60, 10)} {
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: p},
+			pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: p},
+		)
+	}
+	press := func(tag event.Tag) int {
+		n := 0
+		for _, e := range gtx.Events(tag) {
+			if e, ok := e.(pointer.Event); ok && e.Type == pointer.Press {
+				n++
+			}
+		}
+		return n
+	}
+	if n := press(below); n != 1 {
+		t.Errorf("got %d presses below; expected 1", n)
+	}
+	if n := press(above); n != 1 {
+		t.Errorf("got %d presses above; expected 1", n)
+	}
+}
+
+func BenchmarkInset(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		in   Inset
+	}{
+		{"Zero", Inset{}},
+		{"Uniform", UniformInset(10)},
+	} {
+		in := bench.in
+		b.Run(bench.name, func(b *testing.B) {
+			gtx := Context{
+				Ops:         new(op.Ops),
+				Constraints: Constraints{Max: image.Pt(100, 100)},
+			}
+			w := func(gtx Context) Dimensions {
+				return Dimensions{Size: gtx.Constraints.Max}
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				gtx.Ops.Reset()
+				in.Layout(gtx, w)
+			}
+			b.ReportMetric(float64(countOps(gtx.Ops)), "ops/layout")
+		})
+	}
+}
+
+func TestScaled(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Min: image.Pt(10, 0), Max: image.Pt(100, Inf)},
+	}
+	var cs Constraints
+	dims := gtx.Scaled(2, func(gtx Context) Dimensions {
+		cs = gtx.Constraints
+		return Dimensions{Size: image.Pt(30, 15), Baseline: 5}
+	})
+	if exp := (Constraints{Min: image.Pt(5, 0), Max: image.Pt(50, Inf)}); cs != exp {
+		t.Errorf("got constraints %v; expected %v", cs, exp)
+	}
+	if exp := (Dimensions{Size: image.Pt(60, 30), Baseline: 10, LastBaseline: 10}); dims != exp {
+		t.Errorf("got %+v; expected %+v", dims, exp)
+	}
+	// Zooming out doesn't extend large maximums beyond Inf.
+	gtx.Constraints = Constraints{Max: image.Pt(100, 600000)}
+	gtx.Scaled(0.5, func(gtx Context) Dimensions {
+		cs = gtx.Constraints
+		return Dimensions{}
+	})
+	if exp := (Constraints{Max: image.Pt(200, Inf)}); cs != exp {
+		t.Errorf("got constraints %v; expected %v", cs, exp)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	exp := Dimensions{Size: image.Pt(10, 20)}
+	dims, call := gtx.Record(func(gtx Context) Dimensions {
+		op.InvalidateOp{}.Add(gtx.Ops)
+		return exp
+	})
+	if dims != exp {
+		t.Errorf("got %+v; expected %+v", dims, exp)
+	}
+	if n := countOps(gtx.Ops); n != 0 {
+		t.Errorf("recorded operations were added before the call")
+	}
+	call.Add(gtx.Ops)
+	if n := countOps(gtx.Ops); n != 1 {
+		t.Errorf("got %d recorded operations; expected 1", n)
+	}
+}
+
+func TestInf(t *testing.T) {
+	// Inf is an integer, like the constraints it bounds.
+	if inf := Inf; reflect.TypeOf(inf).Kind() != reflect.Int {
+		t.Errorf("got Inf of type %T; expected int", inf)
+	}
+	cs := Constraints{Min: image.Pt(10, 10), Max: image.Pt(100, Inf)}
+	if got, exp := cs.SubMax(image.Pt(20, 20)), (Constraints{Min: image.Pt(10, 10), Max: image.Pt(80, Inf)}); got != exp {
+		t.Errorf("SubMax: got %v; expected %v", got, exp)
+	}
+	if got, exp := cs.Constrain(image.Pt(200, 5000)), image.Pt(100, 5000); got != exp {
+		t.Errorf("Constrain: got %v; expected %v", got, exp)
+	}
+	gtx := Context{Ops: new(op.Ops), Constraints: cs}
+	var inner Constraints
+	UniformInset(10).Layout(gtx, func(gtx Context) Dimensions {
+		inner = gtx.Constraints
+		return Dimensions{}
+	})
+	if exp := (Constraints{Min: image.Pt(10, 10), Max: image.Pt(80, Inf)}); inner != exp {
+		t.Errorf("Inset: got %v; expected %v", inner, exp)
+	}
+}
+
+func TestFits(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Min: image.Pt(10, 0), Max: image.Pt(100, 50)},
+	}
+	for _, tc := range []struct {
+		size image.Point
+		fits bool
+	}{
+		{image.Pt(50, 50), true},
+		{image.Pt(101, 10), false},
+		{image.Pt(50, 51), false},
+		{image.Pt(5, 10), false},
+	} {
+		dims, fits := gtx.Fits(func(gtx Context) Dimensions {
+			if gtx.Queue != nil {
+				t.Error("measurement is not disabled")
+			}
+			op.InvalidateOp{}.Add(gtx.Ops)
+			return Dimensions{Size: tc.size}
+		})
+		if dims.Size != tc.size || fits != tc.fits {
+			t.Errorf("%v: got %v, %v; expected %v", tc.size, dims.Size, fits, tc.fits)
+		}
+	}
+	if n := countOps(gtx.Ops); n != 0 {
+		t.Errorf("measurement added %d operations", n)
+	}
+}
+
+func TestConstrained(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(100, 100)),
+	}
+	for _, tc := range []struct {
+		c   Constrained
+		max image.Point
+	}{
+		{Constrained{MaxWidth: 60}, image.Pt(60, 100)},
+		{Constrained{MaxWidth: 60, MaxHeight: 20}, image.Pt(60, 20)},
+		{Constrained{MaxWidth: 200}, image.Pt(100, 100)},
+		{Constrained{}, image.Pt(100, 100)},
+	} {
+		var max image.Point
+		c := tc.c
+		c.Align = Center
+		dims := c.Layout(gtx, func(gtx Context) Dimensions {
+			max = gtx.Constraints.Max
+			return Dimensions{Size: max}
+		})
+		if max != tc.max {
+			t.Errorf("%+v: got max constraint %v; expected %v", tc.c, max, tc.max)
+		}
+		if exp := image.Pt(100, 100); dims.Size != exp {
+			t.Errorf("%+v: got size %v; expected %v", tc.c, dims.Size, exp)
+		}
+	}
+}
+
+func TestNewContextInsets(t *testing.T) {
+	o := new(op.Ops)
+	e := system.FrameEvent{
+		Metric: unit.Metric{PxPerDp: 2},
+		Size:   image.Pt(200, 100),
+	}
+	// A zero safe area leaves the layout unchanged.
+	gtx := NewContext(o, e)
+	if got, exp := gtx.Constraints, Exact(e.Size); got != exp {
+		t.Errorf("got constraints %v; expected %v", got, exp)
+	}
+	if n := countOps(o); n != 0 {
+		t.Errorf("got %d operations; expected none", n)
+	}
+	e.Insets = system.Insets{Top: 10, Bottom: 5, Left: 1, Right: 2}
+	gtx = NewContext(o, e)
+	if got, exp := gtx.Constraints, Exact(image.Pt(194, 70)); got != exp {
+		t.Errorf("got inset constraints %v; expected %v", got, exp)
+	}
+	if n := countOps(o); n != 1 {
+		t.Errorf("got %d operations; expected an offset", n)
+	}
+}
+
+func TestNewContextWindowSize(t *testing.T) {
+	e := system.FrameEvent{
+		Size:   image.Pt(200, 100),
+		Insets: system.Insets{Top: 10, Left: 20},
+	}
+	gtx := NewContext(new(op.Ops), e)
+	if got, exp := gtx.WindowSize, e.Size; got != exp {
+		t.Errorf("got window size %v; expected %v", got, exp)
+	}
+	if got, exp := gtx.Constraints, Exact(image.Pt(180, 90)); got != exp {
+		t.Errorf("got constraints %v; expected %v", got, exp)
+	}
+}
+
+func TestRotated(t *testing.T) {
+	for _, tc := range []struct {
+		radians float32
+		cs      Constraints
+		size    image.Point
+	}{
+		{0, Constraints{Max: image.Pt(100, 50)}, image.Pt(40, 10)},
+		{math.Pi / 2, Constraints{Max: image.Pt(50, 100)}, image.Pt(10, 40)},
+		{math.Pi, Constraints{Max: image.Pt(100, 50)}, image.Pt(40, 10)},
+		{-math.Pi / 2, Constraints{Max: image.Pt(50, 100)}, image.Pt(10, 40)},
+		{math.Pi / 4, Constraints{Max: image.Pt(100, 50)}, image.Pt(36, 36)},
+	} {
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Constraints{Max: image.Pt(100, 50)},
+		}
+		var cs Constraints
+		dims := gtx.Rotated(tc.radians, func(gtx Context) Dimensions {
+			cs = gtx.Constraints
+			return Dimensions{Size: image.Pt(40, 10)}
+		})
+		if cs != tc.cs {
+			t.Errorf("%v: got constraints %v; expected %v", tc.radians, cs, tc.cs)
+		}
+		if dims.Size != tc.size {
+			t.Errorf("%v: got size %v; expected %v", tc.radians, dims.Size, tc.size)
+		}
+	}
+	// The bounding box of a widget filling the constraints overflows them
+	// when rotated by other than quarter turns.
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 50)},
+	}
+	dims := gtx.Rotated(math.Pi/4, func(gtx Context) Dimensions {
+		return Dimensions{Size: gtx.Constraints.Max}
+	})
+	if exp := image.Pt(107, 107); dims.Size != exp {
+		t.Errorf("got size %v; expected %v", dims.Size, exp)
+	}
+}
+
+func TestRow(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	var maxes []int
+	widget := func(sz image.Point) Widget {
+		return func(gtx Context) Dimensions {
+			maxes = append(maxes, gtx.Constraints.Max.X)
+			return Dimensions{Size: sz}
+		}
+	}
+	for _, tc := range []struct {
+		widgets []Widget
+		size    image.Point
+		maxes   []int
+	}{
+		{nil, image.Pt(0, 0), nil},
+		{[]Widget{widget(image.Pt(20, 10))}, image.Pt(20, 10), []int{100}},
+		{[]Widget{widget(image.Pt(20, 10)), widget(image.Pt(30, 20)), widget(image.Pt(10, 5))}, image.Pt(76, 20), []int{100, 72, 34}},
+	} {
+		maxes = nil
+		dims := Row{Gap: 8}.Layout(gtx, tc.widgets...)
+		if dims.Size != tc.size {
+			t.Errorf("%d widgets: got size %v; expected %v", len(tc.widgets), dims.Size, tc.size)
+		}
+		if !reflect.DeepEqual(maxes, tc.maxes) {
+			t.Errorf("%d widgets: got max constraints %v; expected %v", len(tc.widgets), maxes, tc.maxes)
+		}
+	}
+}
+
+func TestConstraintsDp(t *testing.T) {
+	gtx := Context{Metric: unit.Metric{PxPerDp: 1.5}}
+	if got, exp := gtx.ExactDp(10, 3), Exact(image.Pt(15, 5)); got != exp {
+		t.Errorf("ExactDp: got %v; expected %v", got, exp)
+	}
+	exp := Constraints{Min: image.Pt(2, 0), Max: image.Pt(150, 5)}
+	if got := gtx.ConstraintsDp(1, 100, .2, 3); got != exp {
+		t.Errorf("ConstraintsDp: got %v; expected %v", got, exp)
+	}
+}
+
+func TestFontScale(t *testing.T) {
+	for _, tc := range []struct {
+		m   unit.Metric
+		exp float32
+	}{
+		{unit.Metric{}, 1},
+		{unit.Metric{PxPerDp: 2, PxPerSp: 2}, 1},
+		{unit.Metric{PxPerDp: 2, PxPerSp: 3}, 1.5},
+		{unit.Metric{PxPerSp: 1.25}, 1.25},
+	} {
+		gtx := Context{Metric: tc.m}
+		if got := gtx.FontScale(); got != tc.exp {
+			t.Errorf("%+v: got %v; expected %v", tc.m, got, tc.exp)
+		}
+	}
+}
+
+func TestFirstThatFits(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(50, 50)},
+	}
+	widget := func(width int) Widget {
+		return func(gtx Context) Dimensions {
+			return Dimensions{Size: image.Pt(width, 10)}
+		}
+	}
+	for _, tc := range []struct {
+		widths []int
+		index  int
+	}{
+		{[]int{40, 20}, 0},
+		{[]int{80, 40, 20}, 1},
+		{[]int{80, 60}, 1},
+	} {
+		var widgets []Widget
+		for _, w := range tc.widths {
+			widgets = append(widgets, widget(w))
+		}
+		i, dims := gtx.FirstThatFits(widgets...)
+		if i != tc.index {
+			t.Errorf("%v: got index %d; expected %d", tc.widths, i, tc.index)
+		}
+		if exp := image.Pt(tc.widths[tc.index], 10); dims.Size != exp {
+			t.Errorf("%v: got size %v; expected %v", tc.widths, dims.Size, exp)
+		}
+	}
+	if i, _ := gtx.FirstThatFits(); i != -1 {
+		t.Errorf("no widgets: got index %d; expected -1", i)
+	}
+}
+
+func TestLayoutFixed(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(50, 50)},
+	}
+	size := image.Pt(80, 60)
+	var cs Constraints
+	dims := gtx.LayoutFixed(size, func(gtx Context) Dimensions {
+		cs = gtx.Constraints
+		return Dimensions{Size: image.Pt(80, 40), Baseline: 4}
+	})
+	if exp := Exact(size); cs != exp {
+		t.Errorf("got constraints %v; expected %v", cs, exp)
+	}
+	if exp := (Dimensions{Size: size, Baseline: 24, LastBaseline: 24}); dims != exp {
+		t.Errorf("got %+v; expected %+v", dims, exp)
+	}
+}
+
+func TestFraction(t *testing.T) {
+	gtx := Context{Constraints: Constraints{Max: image.Pt(100, Inf)}}
+	for _, tc := range []struct {
+		f   float32
+		exp int
+	}{
+		{0, 0}, {.3, 30}, {.256, 26}, {1, 100}, {1.5, 100}, {-1, 0},
+	} {
+		if got := gtx.Fraction(Horizontal, tc.f); got != tc.exp {
+			t.Errorf("Fraction(%v): got %d; expected %d", tc.f, got, tc.exp)
+		}
+	}
+	if got := gtx.Fraction(Vertical, .5); got != 0 {
+		t.Errorf("unbounded Fraction: got %d; expected 0", got)
+	}
+	// Parts sized by cumulative fractions fill the space.
+	gtx.Constraints.Max.X = 101
+	fracs := []float32{1. / 3, 1. / 3, 1. / 3}
+	var sum float32
+	total, prev := 0, 0
+	for _, f := range fracs {
+		sum += f
+		end := gtx.Fraction(Horizontal, sum)
+		total += end - prev
+		prev = end
+	}
+	if total != 101 {
+		t.Errorf("got parts summing to %d; expected 101", total)
+	}
+}
+
+func TestSplitPane(t *testing.T) {
+	r := new(router.Router)
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(210, 50)),
+		Queue:       r,
+	}
+	var sizes []int
+	pane := func(gtx Context) Dimensions {
+		sizes = append(sizes, gtx.Constraints.Max.X)
+		return Dimensions{Size: gtx.Constraints.Min}
+	}
+	s := &SplitPane{Ratio: .5, HandleSize: 10, MinSize: 20}
+	layout := func() {
+		sizes = nil
+		gtx.Ops.Reset()
+		if dims := s.Layout(gtx, pane, pane); dims.Size != gtx.Constraints.Max {
+			t.Errorf("got size %v; expected %v", dims.Size, gtx.Constraints.Max)
+		}
+	}
+	layout()
+	if exp := []int{100, 100}; !reflect.DeepEqual(sizes, exp) {
+		t.Errorf("got pane sizes %v; expected %v", sizes, exp)
+	}
+	drag := func(from, to float32) {
+		r.Frame(gtx.Ops)
+		r.Queue(
+			pointer.Event{Type: pointer.Press, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(from, 10)},
+			pointer.Event{Type: pointer.Move, Source: pointer.Mouse, Buttons: pointer.ButtonPrimary, Position: f32.Pt(to, 10)},
+			pointer.Event{Type: pointer.Release, Source: pointer.Mouse, Position: f32.Pt(to, 10)},
+		)
+		layout()
+	}
+	// Drag the handle 50 pixels towards the end.
+	drag(105, 155)
+	if exp := []int{150, 50}; !reflect.DeepEqual(sizes, exp) {
+		t.Errorf("got pane sizes %v after drag; expected %v", sizes, exp)
+	}
+	// Drag past the end; the second pane keeps its minimum size.
+	drag(155, 300)
+	if exp := []int{180, 20}; !reflect.DeepEqual(sizes, exp) {
+		t.Errorf("got pane sizes %v after drag to the end; expected %v", sizes, exp)
+	}
+}
+
+func TestConstraintsAddMax(t *testing.T) {
+	cs := Constraints{Min: image.Pt(20, 10), Max: image.Pt(50, Inf)}
+	for _, tc := range []struct {
+		delta image.Point
+		exp   Constraints
+	}{
+		{image.Pt(10, 10), Constraints{Min: image.Pt(20, 10), Max: image.Pt(60, Inf)}},
+		{image.Pt(Inf, 0), Constraints{Min: image.Pt(20, 10), Max: image.Pt(Inf, Inf)}},
+		// Negative deltas shrink like SubMax.
+		{image.Pt(-40, -10), cs.SubMax(image.Pt(40, 10))},
+		{image.Pt(-60, 0), Constraints{Min: image.Pt(0, 10), Max: image.Pt(0, Inf)}},
+	} {
+		if got := cs.AddMax(tc.delta); got != tc.exp {
+			t.Errorf("AddMax(%v): got %v; expected %v", tc.delta, got, tc.exp)
+		}
+	}
+	// Maximums above Inf are finite and not clamped to Inf.
+	large := Constraints{Max: image.Pt(100, 2*Inf)}
+	if got, exp := large.SubMax(image.Point{}), large; got != exp {
+		t.Errorf("SubMax of zero: got %v; expected %v", got, exp)
+	}
+	if got, exp := large.AddMax(image.Pt(0, 10)), (Constraints{Max: image.Pt(100, 2*Inf+10)}); got != exp {
+		t.Errorf("AddMax above Inf: got %v; expected %v", got, exp)
+	}
+	gtx := Context{Ops: new(op.Ops), Constraints: large}
+	var inner Constraints
+	UniformInset(10).Layout(gtx, func(gtx Context) Dimensions {
+		inner = gtx.Constraints
+		return Dimensions{}
+	})
+	if exp := (Constraints{Max: image.Pt(80, 2*Inf-20)}); inner != exp {
+		t.Errorf("Inset: got %v; expected %v", inner, exp)
+	}
+}
+
+func TestOffsetBatch(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	var got []image.Point
+	w := func(gtx Context) Dimensions {
+		got = append(got, gtx.Constraints.Max)
+		return Dimensions{Size: image.Pt(10, 10)}
+	}
+	offsets := []image.Point{{0, 0}, {20, 5}, {5, 30}}
+	dims := gtx.OffsetBatch(offsets, []Widget{w, w, w})
+	if exp := image.Pt(30, 40); dims.Size != exp {
+		t.Errorf("got size %v; expected %v", dims.Size, exp)
+	}
+	if len(got) != len(offsets) {
+		t.Errorf("laid out %d widgets; expected %d", len(got), len(offsets))
+	}
+}
+
+func BenchmarkOffsetBatch(b *testing.B) {
+	const n = 1000
+	offsets := make([]image.Point, n)
+	widgets := make([]Widget, n)
+	w := func(gtx Context) Dimensions {
+		return Dimensions{Size: image.Pt(10, 10)}
+	}
+	for i := range offsets {
+		offsets[i] = image.Pt(0, i*10)
+		widgets[i] = w
+	}
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Constraints{Max: image.Pt(100, 100)},
+	}
+	b.Run("Offset", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			gtx.Ops.Reset()
+			for j, w := range widgets {
+				gtx.Offset(offsets[j], w)
+			}
+		}
+		b.ReportMetric(float64(countOps(gtx.Ops)), "ops/layout")
+	})
+	b.Run("Batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			gtx.Ops.Reset()
+			gtx.OffsetBatch(offsets, widgets)
+		}
+		b.ReportMetric(float64(countOps(gtx.Ops)), "ops/layout")
+	})
+}
+
+func TestSquare(t *testing.T) {
+	for _, tc := range []struct {
+		sq   Square
+		max  image.Point
+		side int
+	}{
+		// Landscape.
+		{Square{Align: Center}, image.Pt(100, 40), 40},
+		// Portrait.
+		{Square{Align: N}, image.Pt(40, 100), 40},
+		{Square{Size: 20, Align: SE}, image.Pt(100, 40), 20},
+		// The size is limited to the smaller constraint.
+		{Square{Size: 50, Align: Center}, image.Pt(40, 100), 40},
+		// Unbounded axis.
+		{Square{}, image.Pt(40, Inf), 40},
+	} {
+		gtx := Context{
+			Ops:         new(op.Ops),
+			Constraints: Exact(tc.max),
+		}
+		var cs Constraints
+		dims := tc.sq.Layout(gtx, func(gtx Context) Dimensions {
+			cs = gtx.Constraints
+			return Dimensions{Size: gtx.Constraints.Min}
+		})
+		if exp := Exact(image.Pt(tc.side, tc.side)); cs != exp {
+			t.Errorf("%+v in %v: got constraints %v; expected %v", tc.sq, tc.max, cs, exp)
+		}
+		if dims.Size != tc.max {
+			t.Errorf("%+v in %v: got size %v; expected %v", tc.sq, tc.max, dims.Size, tc.max)
+		}
+	}
+}
+
+// decodeOps returns the types of the operations in o, following
+// calls.
+func decodeOps(o *op.Ops) []ops.OpType {
+	var r ops.Reader
+	r.Reset(&o.Internal)
+	var types []ops.OpType
+	for encOp, ok := r.Decode(); ok; encOp, ok = r.Decode() {
+		types = append(types, ops.OpType(encOp.Data[0]))
+	}
+	return types
+}
+
+// countOps returns the number of operations in o, following calls.
+func countOps(o *op.Ops) int {
+	return len(decodeOps(o))
+}
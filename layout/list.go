@@ -28,6 +28,13 @@ type List struct {
 	ScrollToEnd bool
 	// Alignment is the cross axis alignment of list elements.
 	Alignment Alignment
+	// Header, if set, reports whether the element at an index is a
+	// section header. The header of the section at the leading edge of
+	// the list is kept pinned to that edge, and is pushed out by the
+	// header of the next section as it scrolls into place. A pinned
+	// header that is also a visible list element is laid out once, and
+	// drawn only at its pinned position.
+	Header func(index int) bool
 
 	cs          Constraints
 	scroll      gesture.Scroll
@@ -45,8 +52,20 @@ type List struct {
 	maxSize  int
 	children []scrollChild
 	dir      iterationDir
+	// viewSize is the main axis size of the laid out list.
+	viewSize int
+	// header is the index of the most recently pinned header, where the
+	// search for the next one starts.
+	header int
+	// sizeSum and sizeCount accumulate the main axis sizes of the
+	// children laid out in recent frames, for estimating Position.Length.
+	sizeSum, sizeCount int
 }
 
+// maxSizeCount bounds the number of child sizes in the estimate of
+// Position.Length, so that it follows changes to the content.
+const maxSizeCount = 1024
+
 // ListElement is a function that computes the dimensions of
 // a list element.
 type ListElement func(gtx Context, index int) Dimensions
@@ -75,6 +94,7 @@ type Position struct {
 	// Count is the number of visible children.
 	Count int
 	// Length is the estimated total size of all children, measured in pixels.
+	// The estimate is refined as more children are laid out across frames.
 	Length int
 }
 
@@ -84,8 +104,6 @@ const (
 	iterateBackward
 )
 
-const inf = 1e6
-
 // init prepares the list for iterating through its children with next.
 func (l *List) init(gtx Context, len int) {
 	if l.more() {
@@ -112,7 +130,7 @@ func (l *List) init(gtx Context, len int) {
 func (l *List) Layout(gtx Context, len int, w ListElement) Dimensions {
 	l.init(gtx, len)
 	crossMin, crossMax := l.Axis.crossConstraint(gtx.Constraints)
-	gtx.Constraints = l.Axis.constraints(0, inf, crossMin, crossMax)
+	gtx.Constraints = l.Axis.constraints(0, Inf, crossMin, crossMax)
 	macro := op.Record(gtx.Ops)
 	laidOutTotalLength := 0
 	numLaidOut := 0
@@ -126,12 +144,105 @@ func (l *List) Layout(gtx Context, len int, w ListElement) Dimensions {
 		numLaidOut++
 	}
 
-	if numLaidOut > 0 {
-		l.Position.Length = laidOutTotalLength * len / numLaidOut
+	l.sizeSum += laidOutTotalLength
+	l.sizeCount += numLaidOut
+	if l.sizeCount > maxSizeCount {
+		l.sizeSum /= 2
+		l.sizeCount /= 2
+	}
+	if l.sizeCount > 0 {
+		// Avoid overflowing 32-bit ints for long lists.
+		l.Position.Length = int(int64(l.sizeSum) * int64(len) / int64(l.sizeCount))
 	} else {
 		l.Position.Length = 0
 	}
-	return l.layout(gtx.Ops, macro)
+	// The index of the first laid out child, before layout skips the
+	// invisible children.
+	first := l.Position.First
+	h, header := -1, scrollChild{}
+	if l.Header != nil {
+		h, header = l.pinHeader(gtx, w, first)
+	}
+	dims := l.layout(gtx.Ops, macro)
+	if h >= 0 {
+		l.drawHeader(gtx.Ops, header, first, h, dims.Size)
+	}
+	return dims
+}
+
+// pinHeader lays out the header to pin and returns its index, or -1 if
+// there is none. A header among the laid out children is taken from them,
+// and its in-list copy is not drawn. The children are indexed from first.
+func (l *List) pinHeader(gtx Context, w ListElement, first int) (int, scrollChild) {
+	// Find the first visible child the way layout skips invisible ones.
+	idx, off := first, l.Position.Offset
+	for _, child := range l.children {
+		sz := l.Axis.Convert(child.size).X
+		if off < sz {
+			break
+		}
+		idx++
+		off -= sz
+	}
+	if idx >= l.len {
+		idx = l.len - 1
+	}
+	h := l.findHeader(idx)
+	if h < 0 {
+		return -1, scrollChild{}
+	}
+	if i := h - first; i >= 0 && i < len(l.children) {
+		child := l.children[i]
+		l.children[i].call = op.CallOp{}
+		return h, child
+	}
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx, h)
+	return h, scrollChild{size: dims.Size, call: macro.Stop()}
+}
+
+// findHeader returns the index of the header at or before index, or -1.
+// The search stops at the previously pinned header, if it still applies,
+// so scrolling forward within or across sections doesn't scan back to the
+// start of the list.
+func (l *List) findHeader(index int) int {
+	lo := -1
+	if c := l.header; c >= 0 && c <= index && l.Header(c) {
+		lo = c
+	}
+	h := index
+	for h > lo && !l.Header(h) {
+		h--
+	}
+	if h >= 0 {
+		l.header = h
+	}
+	return h
+}
+
+// drawHeader draws the pinned header at index h above the list content
+// of the given size.
+func (l *List) drawHeader(ops *op.Ops, header scrollChild, first, h int, size image.Point) {
+	sz := l.Axis.Convert(header.size)
+	pos := l.headerPos(first, h, sz.X)
+	cross := l.Alignment.Position(sz.Y, l.Axis.Convert(size).Y, 0, 0)
+	defer clip.Rect(image.Rectangle{Max: size}).Push(ops).Pop()
+	defer op.Offset(l.Axis.Convert(image.Pt(pos, cross))).Push(ops).Pop()
+	header.call.Add(ops)
+}
+
+// headerPos returns the main axis position of the pinned header at index
+// h with main axis size hsize. The header is at the leading edge, unless
+// the following header overlaps it. The children are indexed from first.
+func (l *List) headerPos(first, h, hsize int) int {
+	pos := -l.Position.Offset
+	for i := l.Position.First - first; i >= 0 && i < len(l.children) && pos < hsize; i++ {
+		if idx := first + i; idx > h && l.Header(idx) {
+			return pos - hsize
+		}
+		pos += l.Axis.Convert(l.children[i].size).X
+	}
+	return 0
 }
 
 func (l *List) scrollToEnd() bool {
@@ -280,13 +391,7 @@ func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 	pos := -l.Position.Offset
 	layout := func(child scrollChild) {
 		sz := l.Axis.Convert(child.size)
-		var cross int
-		switch l.Alignment {
-		case End:
-			cross = maxCross - sz.Y
-		case Middle:
-			cross = (maxCross - sz.Y) / 2
-		}
+		cross := l.Alignment.Position(sz.Y, maxCross, 0, 0)
 		childSize := sz.X
 		min := pos
 		if min < 0 {
@@ -328,11 +433,12 @@ func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 	} else if maxCross > crossMax {
 		maxCross = crossMax
 	}
+	l.viewSize = pos
 	dims := l.Axis.Convert(image.Pt(pos, maxCross))
 	call := macro.Stop()
 	defer clip.Rect(image.Rectangle{Max: dims}).Push(ops).Pop()
 
-	min, max := int(-inf), int(inf)
+	min, max := -Inf, Inf
 	if l.Position.First == 0 {
 		// Use the size of the invisible part as scroll boundary.
 		min = -l.Position.Offset
@@ -356,6 +462,34 @@ func (l *List) layout(ops *op.Ops, macro op.MacroOp) Dimensions {
 	return Dimensions{Size: dims}
 }
 
+// ScrollFraction returns the fraction of the list content scrolled
+// before the viewport and the fraction of the content visible in the
+// viewport, as of the most recent Layout. Both are in the range [0,1].
+//
+// The total size of the content is estimated in Position.Length from the
+// average size of the children laid out in recent frames, so the
+// fractions are approximate for children of differing sizes.
+func (l *List) ScrollFraction() (offset, visible float32) {
+	length := float32(l.Position.Length)
+	if l.len == 0 || length <= 0 {
+		return 0, 1
+	}
+	meanSize := length / float32(l.len)
+	offset = (float32(l.Position.First)*meanSize + float32(l.Position.Offset)) / length
+	visible = float32(l.viewSize) / length
+	return clampUnit(offset), clampUnit(visible)
+}
+
+func clampUnit(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
 // ScrollBy scrolls the list by a relative amount of items.
 //
 // Fractional scrolling may be inaccurate for items of differing
@@ -195,3 +195,112 @@ func TestExtraChildren(t *testing.T) {
 		t.Errorf("laid out %d of %d children", count, all)
 	}
 }
+
+func TestListScrollFraction(t *testing.T) {
+	var l List
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(20, 100)),
+	}
+	if offset, visible := l.ScrollFraction(); offset != 0 || visible != 1 {
+		t.Errorf("empty list: got fractions (%v, %v); expected (0, 1)", offset, visible)
+	}
+	l.Axis = Vertical
+	l.Position.First = 5
+	l.Layout(gtx, 20, func(gtx Context, idx int) Dimensions {
+		return Dimensions{Size: image.Pt(20, 50)}
+	})
+	offset, visible := l.ScrollFraction()
+	if exp := float32(5) / 20; offset != exp {
+		t.Errorf("got offset fraction %v; expected %v", offset, exp)
+	}
+	if exp := float32(100) / (20 * 50); visible != exp {
+		t.Errorf("got visible fraction %v; expected %v", visible, exp)
+	}
+}
+
+func TestListLength(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(20, 20)),
+	}
+	// The first half of the children are smaller than the second.
+	w := func(gtx Context, idx int) Dimensions {
+		if idx < 5 {
+			return Dimensions{Size: image.Pt(20, 10)}
+		}
+		return Dimensions{Size: image.Pt(20, 30)}
+	}
+	l := List{Axis: Vertical}
+	l.Layout(gtx, 10, w)
+	if exp := 10 * 10; l.Position.Length != exp {
+		t.Errorf("got length %d; expected %d", l.Position.Length, exp)
+	}
+	// Laying out the larger children refines the estimate instead of
+	// replacing it.
+	l.Position = Position{First: 8}
+	l.Layout(gtx, 10, w)
+	if exp := 5*10 + 5*30; l.Position.Length != exp {
+		t.Errorf("got length %d; expected %d", l.Position.Length, exp)
+	}
+	// Long lists don't overflow the estimate.
+	gtx.Constraints = Exact(image.Pt(20, 1000))
+	l = List{Axis: Vertical}
+	for i := 0; i < 100; i++ {
+		l.Layout(gtx, 100000, func(gtx Context, idx int) Dimensions {
+			return Dimensions{Size: image.Pt(20, 100)}
+		})
+	}
+	if exp := 100000 * 100; l.Position.Length != exp {
+		t.Errorf("got length %d; expected %d", l.Position.Length, exp)
+	}
+}
+
+func TestListHeader(t *testing.T) {
+	gtx := Context{
+		Ops:         new(op.Ops),
+		Constraints: Exact(image.Pt(20, 30)),
+	}
+	l := List{
+		Axis: Vertical,
+		Header: func(index int) bool {
+			return index%5 == 0
+		},
+	}
+	tests := []struct {
+		first, offset int
+		header, pos   int
+	}{
+		{first: 0, offset: 0, header: 0, pos: 0},
+		{first: 2, offset: 0, header: 0, pos: 0},
+		{first: 3, offset: 5, header: 0, pos: 0},
+		// The next header pushes the pinned header out.
+		{first: 4, offset: 5, header: 0, pos: -5},
+		{first: 5, offset: 2, header: 5, pos: 0},
+		// An offset past the first child skips it.
+		{first: 4, offset: 12, header: 5, pos: 0},
+		// Scrolling back before the previously pinned header.
+		{first: 2, offset: 0, header: 0, pos: 0},
+	}
+	for i, test := range tests {
+		l.Position = Position{First: test.first, Offset: test.offset}
+		first := test.first
+		calls := make(map[int]int)
+		l.Layout(gtx, 20, func(gtx Context, idx int) Dimensions {
+			if l.more() && idx < first {
+				first = idx
+			}
+			calls[idx]++
+			return Dimensions{Size: image.Pt(20, 10)}
+		})
+		if l.header != test.header {
+			t.Errorf("%d: got header %d; expected %d", i, l.header, test.header)
+		}
+		if calls[test.header] != 1 {
+			t.Errorf("%d: header laid out %d times; expected once", i, calls[test.header])
+		}
+		if pos := l.headerPos(first, test.header, 10); pos != test.pos {
+			t.Errorf("%d: got header position %d; expected %d", i, pos, test.pos)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+//go:build !debuglayout
+
+package layout
+
+// DebugBounds enables the outlines drawn by Debug. It is true when
+// building with the debuglayout tag, and otherwise Debug compiles to a
+// plain call of its widget.
+const DebugBounds = false
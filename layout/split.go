@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Unlicense OR MIT
+
+package layout
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/gesture"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+)
+
+// SplitPane lays out two widgets next to each other along an axis,
+// separated by a handle that resizes them when dragged.
+type SplitPane struct {
+	// Axis is the main axis, either Horizontal or Vertical.
+	Axis Axis
+	// Ratio is the fraction of the space, less the handle, taken by the
+	// first widget. It is updated by dragging the handle.
+	Ratio float32
+	// HandleSize is the main axis size of the handle.
+	HandleSize unit.Dp
+	// MinSize is the minimum main axis size of each widget. It is
+	// reduced if the two widgets can't both fit it.
+	MinSize unit.Dp
+
+	drag gesture.Drag
+	// grab is the main axis position of the pointer within the handle
+	// when the drag started.
+	grab float32
+}
+
+// Layout the two widgets. The widgets are laid out with exact main axis
+// constraints and the cross axis constraints of gtx. The handle is an
+// input area between the widgets and draws nothing.
+func (s *SplitPane) Layout(gtx Context, first, second Widget) Dimensions {
+	_, mainMax := s.Axis.mainConstraint(gtx.Constraints)
+	crossMin, crossMax := s.Axis.crossConstraint(gtx.Constraints)
+	handle := gtx.Dp(s.HandleSize)
+	space := mainMax - handle
+	if space < 0 {
+		space = 0
+	}
+	// The drag events are relative to the handle as laid out in the
+	// previous frame, at the size before the events.
+	off := s.firstSize(gtx, space)
+	for _, e := range s.drag.Events(gtx.Metric, gtx, gesture.Axis(s.Axis)) {
+		pos := s.Axis.FConvert(e.Position).X
+		switch e.Type {
+		case pointer.Press:
+			s.grab = pos
+		case pointer.Drag:
+			if space > 0 {
+				s.Ratio = (float32(off) + pos - s.grab) / float32(space)
+			}
+		}
+	}
+	if s.Ratio < 0 {
+		s.Ratio = 0
+	}
+	if s.Ratio > 1 {
+		s.Ratio = 1
+	}
+	size := s.firstSize(gtx, space)
+
+	cgtx := gtx
+	cgtx.Constraints = s.Axis.constraints(size, size, crossMin, crossMax)
+	dims := first(cgtx)
+	cross := s.Axis.Convert(dims.Size).Y
+
+	cgtx.Constraints = s.Axis.constraints(space-size, space-size, crossMin, crossMax)
+	trans := op.Offset(s.Axis.Convert(image.Pt(size+handle, 0))).Push(gtx.Ops)
+	dims = second(cgtx)
+	trans.Pop()
+	if c := s.Axis.Convert(dims.Size).Y; c > cross {
+		cross = c
+	}
+	if cross < crossMin {
+		cross = crossMin
+	}
+	if cross > crossMax {
+		cross = crossMax
+	}
+
+	trans = op.Offset(s.Axis.Convert(image.Pt(size, 0))).Push(gtx.Ops)
+	area := clip.Rect{Max: s.Axis.Convert(image.Pt(handle, cross))}.Push(gtx.Ops)
+	cursor := pointer.CursorColResize
+	if s.Axis == Vertical {
+		cursor = pointer.CursorRowResize
+	}
+	cursor.Add(gtx.Ops)
+	s.drag.Add(gtx.Ops)
+	area.Pop()
+	trans.Pop()
+
+	return Dimensions{Size: s.Axis.Convert(image.Pt(mainMax, cross))}
+}
+
+// Dragging reports whether the handle is being dragged.
+func (s *SplitPane) Dragging() bool {
+	return s.drag.Dragging()
+}
+
+// firstSize returns the main axis size of the first widget for Ratio,
+// limited by the minimum size.
+func (s *SplitPane) firstSize(gtx Context, space int) int {
+	min := gtx.Dp(s.MinSize)
+	if min > space/2 {
+		min = space / 2
+	}
+	size := int(math.Round(float64(s.Ratio) * float64(space)))
+	if size < min {
+		size = min
+	}
+	if max := space - min; size > max {
+		size = max
+	}
+	return size
+}
@@ -6,10 +6,17 @@ import (
 	"image"
 
 	"gioui.org/op"
+	"gioui.org/op/clip"
 )
 
 // Stack lays out child elements on top of each other,
 // according to an alignment direction.
+//
+// The size of a Stack is the largest size of its Stacked children, which
+// are laid out first with loose constraints. Expanded children are then
+// laid out with their minimum constraints set to that size, so they
+// stretch to cover the Stacked children. An Expanded child larger than
+// the Stacked children enlarges the stack.
 type Stack struct {
 	// Alignment is the direction to align children
 	// smaller than the available space.
@@ -20,6 +27,7 @@ type Stack struct {
 type StackChild struct {
 	expanded bool
 	widget   Widget
+	z        int
 
 	// Scratch space.
 	call op.CallOp
@@ -44,6 +52,14 @@ func Expanded(w Widget) StackChild {
 	}
 }
 
+// ZIndex returns a copy of the child with drawing order z. Children are
+// drawn in increasing z order and children with equal z are drawn in the
+// order they are passed to Stack.Layout. The default z is 0.
+func (c StackChild) ZIndex(z int) StackChild {
+	c.z = z
+	return c
+}
+
 // Layout a stack of children. The position of the children are
 // determined by the specified order, but Stacked children are laid out
 // before Expanded children.
@@ -88,33 +104,67 @@ func (s Stack) Layout(gtx Context, children ...StackChild) Dimensions {
 	}
 
 	maxSZ = gtx.Constraints.Constrain(maxSZ)
-	var baseline int
+	var baseline, lastBaseline int
+	minZ, maxZ := 0, 0
 	for _, ch := range children {
-		sz := ch.dims.Size
-		var p image.Point
-		switch s.Alignment {
-		case N, S, Center:
-			p.X = (maxSZ.X - sz.X) / 2
-		case NE, SE, E:
-			p.X = maxSZ.X - sz.X
-		}
-		switch s.Alignment {
-		case W, Center, E:
-			p.Y = (maxSZ.Y - sz.Y) / 2
-		case SW, S, SE:
-			p.Y = maxSZ.Y - sz.Y
-		}
-		trans := op.Offset(p).Push(gtx.Ops)
-		ch.call.Add(gtx.Ops)
-		trans.Pop()
 		if baseline == 0 {
 			if b := ch.dims.Baseline; b != 0 {
-				baseline = b + maxSZ.Y - sz.Y - p.Y
+				p := s.Alignment.Position(ch.dims.Size, maxSZ)
+				baseline = b + maxSZ.Y - ch.dims.Size.Y - p.Y
+				lastBaseline = ch.dims.lastBaseline() + maxSZ.Y - ch.dims.Size.Y - p.Y
+			}
+		}
+		if ch.z < minZ {
+			minZ = ch.z
+		}
+		if ch.z > maxZ {
+			maxZ = ch.z
+		}
+	}
+	// Draw the children for each z in increasing order, without
+	// sorting children.
+	for z := minZ; ; {
+		next := maxZ
+		for _, ch := range children {
+			switch {
+			case ch.z == z:
+				p := s.Alignment.Position(ch.dims.Size, maxSZ)
+				trans := op.Offset(p).Push(gtx.Ops)
+				ch.call.Add(gtx.Ops)
+				trans.Pop()
+			case ch.z > z && ch.z < next:
+				next = ch.z
 			}
 		}
+		if z == maxZ {
+			break
+		}
+		z = next
 	}
 	return Dimensions{
-		Size:     maxSZ,
-		Baseline: baseline,
+		Size:         maxSZ,
+		Baseline:     baseline,
+		LastBaseline: lastBaseline,
 	}
 }
+
+// Background lays out a widget on top of a background sized to fit it.
+type Background struct{}
+
+// Layout a widget and then a background behind it. The background is laid
+// out with its constraints fixed to the size of the widget, and is clipped
+// to that size if it is larger. The dimensions are those of the widget.
+func (Background) Layout(gtx Context, background, widget Widget) Dimensions {
+	macro := op.Record(gtx.Ops)
+	dims := widget(gtx)
+	call := macro.Stop()
+
+	cgtx := gtx
+	cgtx.Constraints = Exact(dims.Size)
+	st := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
+	background(cgtx)
+	st.Pop()
+
+	call.Add(gtx.Ops)
+	return dims
+}
@@ -71,6 +71,7 @@ func (l Label) Layout(gtx layout.Context, lt *text.Shaper, font font.Font, size
 	dims := layout.Dimensions{Size: it.bounds.Size()}
 	dims.Size = cs.Constrain(dims.Size)
 	dims.Baseline = dims.Size.Y - it.baseline
+	dims.LastBaseline = dims.Size.Y - it.lastBaseline
 	clipStack.Pop()
 	return dims
 }
@@ -107,6 +108,9 @@ type textIterator struct {
 	first bool
 	// baseline tracks the location of the first line of text's baseline.
 	baseline int
+	// lastBaseline tracks the location of the last visible line of text's
+	// baseline.
+	lastBaseline int
 }
 
 // processGlyph checks whether the glyph is visible within the iterator's configured
@@ -135,6 +139,7 @@ func (it *textIterator) processGlyph(g text.Glyph, ok bool) (_ text.Glyph, visib
 	if !it.first {
 		it.first = true
 		it.baseline = int(g.Y)
+		it.lastBaseline = int(g.Y)
 		it.bounds = logicalBounds
 	}
 
@@ -144,6 +149,7 @@ func (it *textIterator) processGlyph(g text.Glyph, ok bool) (_ text.Glyph, visib
 	right := logicalBounds.Min.X > it.viewport.Max.X
 	it.visible = !above && !below && !left && !right
 	if it.visible {
+		it.lastBaseline = int(g.Y)
 		it.bounds.Min.X = min(it.bounds.Min.X, logicalBounds.Min.X)
 		it.bounds.Min.Y = min(it.bounds.Min.Y, logicalBounds.Min.Y)
 		it.bounds.Max.X = max(it.bounds.Max.X, logicalBounds.Max.X)
@@ -5,7 +5,12 @@ import (
 	"math"
 	"testing"
 
+	"gioui.org/font"
+	"gioui.org/font/gofont"
+	"gioui.org/layout"
+	"gioui.org/op"
 	"gioui.org/text"
+	"gioui.org/unit"
 	"golang.org/x/image/math/fixed"
 )
 
@@ -166,3 +171,27 @@ func TestGlyphIterator(t *testing.T) {
 		})
 	}
 }
+
+// TestLabelLastBaseline ensures that the last baseline of multi-line text
+// is that of its last line.
+func TestLabelLastBaseline(t *testing.T) {
+	gtx := layout.Context{
+		Ops:         new(op.Ops),
+		Constraints: layout.Constraints{Max: image.Pt(1000, 1000)},
+	}
+	cache := text.NewShaper(gofont.Collection())
+	layoutText := func(txt string) layout.Dimensions {
+		return Label{}.Layout(gtx, cache, font.Font{}, unit.Sp(16), txt, op.CallOp{})
+	}
+	single := layoutText("M")
+	if single.Baseline == 0 || single.LastBaseline != single.Baseline {
+		t.Errorf("single line: got baseline %d and last baseline %d; expected equal and non-zero", single.Baseline, single.LastBaseline)
+	}
+	multi := layoutText("M\nM")
+	if multi.Baseline != multi.Size.Y-single.Size.Y+single.Baseline {
+		t.Errorf("two lines: got baseline %d; expected the first line's", multi.Baseline)
+	}
+	if multi.LastBaseline != single.LastBaseline {
+		t.Errorf("two lines: got last baseline %d; expected %d", multi.LastBaseline, single.LastBaseline)
+	}
+}
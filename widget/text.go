@@ -102,7 +102,12 @@ func (e *textView) Changed() bool {
 // Dimensions returns the dimensions of the visible text.
 func (e *textView) Dimensions() layout.Dimensions {
 	basePos := e.dims.Size.Y - e.dims.Baseline
-	return layout.Dimensions{Size: e.viewSize, Baseline: e.viewSize.Y - basePos}
+	lastPos := e.dims.Size.Y - e.dims.LastBaseline
+	return layout.Dimensions{
+		Size:         e.viewSize,
+		Baseline:     e.viewSize.Y - basePos,
+		LastBaseline: e.viewSize.Y - lastPos,
+	}
 }
 
 // FullDimensions returns the dimensions of all shaped text, including
@@ -499,6 +504,7 @@ func (e *textView) layoutText(lt *text.Shaper) {
 	}
 	dims := layout.Dimensions{Size: it.bounds.Size()}
 	dims.Baseline = dims.Size.Y - it.baseline
+	dims.LastBaseline = dims.Size.Y - it.lastBaseline
 	e.dims = dims
 }
 